@@ -0,0 +1,140 @@
+// Package capacity totals the resources a generated template asks for and checks whether its pods fit a set of nodes
+package capacity
+
+import (
+	"sort"
+
+	"github.com/maleck13/templator/model"
+	"k8s.io/kubernetes/pkg/api/resource"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+const (
+	STORAGE_CLASS_ANNOTATION = "volume.beta.kubernetes.io/storage-class"
+	DEFAULT_STORAGE_CLASS    = "default"
+)
+
+// Workload is what one generated deployment config asks for, per pod and across all of its replicas. The replicas of
+// an autoscaled config are the most its autoscaler scales it to
+type Workload struct {
+	Name         string
+	Replicas     int
+	Autoscaled   bool
+	NodeSelector map[string]string
+	PodRequests  k8.ResourceList
+	PodLimits    k8.ResourceList
+	Requests     k8.ResourceList
+	Limits       k8.ResourceList
+}
+
+// Storage is the storage claimed from one storage class
+type Storage struct {
+	Class  string
+	Claims int
+	Size   resource.Quantity
+}
+
+// Report totals the resources of the generated objects
+type Report struct {
+	Workloads []Workload
+	Requests  k8.ResourceList
+	Limits    k8.ResourceList
+	Storage   []Storage
+}
+
+// Calculate totals the pod resources of the generated deployment configs and the storage of the generated claims.
+// Configs with an autoscaler are counted at its maximum replicas
+func Calculate(objects []runtime.Object) *Report {
+	report := &Report{Requests: k8.ResourceList{}, Limits: k8.ResourceList{}}
+	storage := make(map[string]*Storage)
+	maxReplicas := make(map[string]int)
+	for _, obj := range objects {
+		if hpa, ok := obj.(*autoscaling.HorizontalPodAutoscaler); ok && "DeploymentConfig" == hpa.Spec.ScaleTargetRef.Kind {
+			maxReplicas[hpa.Spec.ScaleTargetRef.Name] = int(hpa.Spec.MaxReplicas)
+		}
+	}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *model.DeploymentConfig:
+			if nil == o.Spec.Template {
+				continue
+			}
+			requests, limits := podResources(o.Spec.Template.Spec)
+			replicas, autoscaled := maxReplicas[o.Name]
+			if !autoscaled {
+				replicas = o.Spec.Replicas
+			}
+			w := Workload{
+				Name:         o.Name,
+				Replicas:     replicas,
+				Autoscaled:   autoscaled,
+				NodeSelector: o.Spec.Template.Spec.NodeSelector,
+				PodRequests:  requests,
+				PodLimits:    limits,
+				Requests:     multiply(requests, replicas),
+				Limits:       multiply(limits, replicas),
+			}
+			add(report.Requests, w.Requests)
+			add(report.Limits, w.Limits)
+			report.Workloads = append(report.Workloads, w)
+		case *k8.PersistentVolumeClaim:
+			class := o.Annotations[STORAGE_CLASS_ANNOTATION]
+			if "" == class {
+				class = DEFAULT_STORAGE_CLASS
+			}
+			s, ok := storage[class]
+			if !ok {
+				s = &Storage{Class: class}
+				storage[class] = s
+			}
+			s.Claims++
+			if q, ok := o.Spec.Resources.Requests[k8.ResourceStorage]; ok {
+				s.Size.Add(q)
+			}
+		}
+	}
+	classes := make([]string, 0, len(storage))
+	for c := range storage {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes)
+	for _, c := range classes {
+		report.Storage = append(report.Storage, *storage[c])
+	}
+	return report
+}
+
+// podResources sums the containers of a pod. A container with a limit but no request is given the limit as its
+// request the same way the scheduler does
+func podResources(spec k8.PodSpec) (requests, limits k8.ResourceList) {
+	requests, limits = k8.ResourceList{}, k8.ResourceList{}
+	for _, c := range spec.Containers {
+		add(limits, c.Resources.Limits)
+		containerRequests := k8.ResourceList{}
+		add(containerRequests, c.Resources.Limits)
+		for name, q := range c.Resources.Requests {
+			containerRequests[name] = *q.Copy()
+		}
+		add(requests, containerRequests)
+	}
+	return requests, limits
+}
+
+// add adds every quantity of from to to
+func add(to, from k8.ResourceList) {
+	for name, q := range from {
+		total := to[name]
+		total.Add(q)
+		to[name] = total
+	}
+}
+
+func multiply(list k8.ResourceList, n int) k8.ResourceList {
+	result := k8.ResourceList{}
+	for name, q := range list {
+		result[name] = *resource.NewMilliQuantity(q.MilliValue()*int64(n), q.Format)
+	}
+	return result
+}
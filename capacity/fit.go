@@ -0,0 +1,161 @@
+package capacity
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// Node is one node of an inventory. Capacity takes cpu, memory and pods
+type Node struct {
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Capacity k8.ResourceList   `json:"capacity"`
+}
+
+// Placement is the node a pod was fitted to or the reason it did not fit
+type Placement struct {
+	Pod    string
+	Node   string
+	Reason string
+}
+
+// FitResult is the outcome of fitting the pods of a report to an inventory
+type FitResult struct {
+	Placements  []Placement
+	Allocated   map[string]k8.ResourceList
+	Unscheduled int
+}
+
+// LoadInventory reads a json or yaml list of nodes
+func LoadInventory(file string) ([]Node, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to read inventory %s %s", file, err.Error())
+	}
+	for i, n := range nodes {
+		if "" == n.Name {
+			return nil, fmt.Errorf("node %d of inventory %s has no name", i, file)
+		}
+	}
+	return nodes, nil
+}
+
+type pod struct {
+	name         string
+	nodeSelector map[string]string
+	requests     k8.ResourceList
+}
+
+// Fit places every replica of the workloads on the nodes by their requests, largest pods first and each on the
+// matching node with the most cpu left, similar to how the scheduler spreads pods
+func Fit(workloads []Workload, nodes []Node) *FitResult {
+	var pods []pod
+	for _, w := range workloads {
+		for i := 0; i < w.Replicas; i++ {
+			name := w.Name
+			if w.Replicas > 1 {
+				name = fmt.Sprintf("%s-%d", w.Name, i+1)
+			}
+			pods = append(pods, pod{name: name, nodeSelector: w.NodeSelector, requests: w.PodRequests})
+		}
+	}
+	sort.Stable(bySize(pods))
+
+	result := &FitResult{Allocated: make(map[string]k8.ResourceList, len(nodes))}
+	podCounts := make(map[string]int64, len(nodes))
+	for _, n := range nodes {
+		result.Allocated[n.Name] = k8.ResourceList{}
+	}
+	for _, p := range pods {
+		var (
+			best     *Node
+			bestFree int64
+			reason   = "no node matches the node selector"
+		)
+		for i := range nodes {
+			n := &nodes[i]
+			if !matches(p.nodeSelector, n.Labels) {
+				continue
+			}
+			allocated := result.Allocated[n.Name]
+			if insufficient := insufficientResource(p.requests, allocated, n.Capacity, podCounts[n.Name]); "" != insufficient {
+				reason = "insufficient " + insufficient
+				continue
+			}
+			free := freeMilli(n.Capacity, allocated, k8.ResourceCPU)
+			if nil == best || free > bestFree {
+				best, bestFree = n, free
+			}
+		}
+		if nil == best {
+			result.Placements = append(result.Placements, Placement{Pod: p.name, Reason: reason})
+			result.Unscheduled++
+			continue
+		}
+		add(result.Allocated[best.Name], p.requests)
+		podCounts[best.Name]++
+		result.Placements = append(result.Placements, Placement{Pod: p.name, Node: best.Name})
+	}
+	return result
+}
+
+// insufficientResource returns the resource the node does not have enough of for the pod. Resources the node does
+// not list a capacity for are not limited
+func insufficientResource(requests, allocated, capacity k8.ResourceList, pods int64) string {
+	if q, ok := capacity[k8.ResourcePods]; ok && pods+1 > q.Value() {
+		return string(k8.ResourcePods)
+	}
+	for _, name := range []k8.ResourceName{k8.ResourceCPU, k8.ResourceMemory} {
+		c, ok := capacity[name]
+		if !ok {
+			continue
+		}
+		r, a := requests[name], allocated[name]
+		if a.MilliValue()+r.MilliValue() > c.MilliValue() {
+			return string(name)
+		}
+	}
+	return ""
+}
+
+func freeMilli(capacity, allocated k8.ResourceList, name k8.ResourceName) int64 {
+	c, a := capacity[name], allocated[name]
+	return c.MilliValue() - a.MilliValue()
+}
+
+func matches(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type bySize []pod
+
+func (bs bySize) Len() int {
+	return len(bs)
+}
+
+func (bs bySize) Swap(i, j int) {
+	bs[i], bs[j] = bs[j], bs[i]
+}
+
+func (bs bySize) Less(i, j int) bool {
+	for _, name := range []k8.ResourceName{k8.ResourceCPU, k8.ResourceMemory} {
+		qi, qj := bs[i].requests[name], bs[j].requests[name]
+		if c := qi.Cmp(qj); c != 0 {
+			return c > 0
+		}
+	}
+	return false
+}
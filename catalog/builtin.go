@@ -0,0 +1,198 @@
+package catalog
+
+import (
+	"github.com/maleck13/templator/model"
+	"k8s.io/kubernetes/pkg/api/resource"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+// builtin returns the starters that are part of the binary. They are built on every call so a caller can change
+// them freely. The images are the centos ones as they run as the arbitrary user OpenShift assigns
+func builtin() []*Starter {
+	return []*Starter{
+		redis(),
+		postgresql(),
+		mongodb(),
+		nginx(),
+	}
+}
+
+func redis() *Starter {
+	appTemp := starterTemplate("redis", "redis key value store with persistent storage")
+	dc := deployment("redis", "centos/redis-${REDIS_VERSION}-centos7", k8.ContainerPort{Name: "redis", ContainerPort: 6379})
+	dc.Spec.ReplicaStrategy = model.ReplicationStrategy_Single
+	dc.Spec.Strategy.Type = model.DeploymentStrategyTypeRecreate
+	container := &dc.Spec.Template.Spec.Containers[0]
+	container.Env = []k8.EnvVar{{Name: "REDIS_PASSWORD", Value: "${REDIS_PASSWORD}"}}
+	container.Resources = resources("100m", "256Mi", "", "512Mi")
+	mount(dc, "redis-data", "redis-data", "/var/lib/redis/data")
+	appTemp.DeploymentConfigs["redis"] = dc
+	appTemp.Services["redis"] = service("redis", "redis", "", 6379)
+	appTemp.PersistentVolumes["redis-data"] = claim("redis-data", "1Gi")
+	appTemp.Parameters = []*model.Parameter{
+		{Name: "REDIS_VERSION", Description: "the redis version of the centos/redis image", Value: "32", Required: true},
+		{Name: "REDIS_PASSWORD", Description: "the password clients authenticate with", Generate: "expression", From: "[a-zA-Z0-9]{16}", Required: true},
+	}
+	return &Starter{Name: "redis", Description: appTemp.Annotations["description"], Source: SOURCE_BUILTIN, Template: appTemp}
+}
+
+func postgresql() *Starter {
+	appTemp := starterTemplate("postgresql", "postgresql database with persistent storage")
+	dc := deployment("postgresql", "centos/postgresql-${POSTGRESQL_VERSION}-centos7", k8.ContainerPort{Name: "postgresql", ContainerPort: 5432})
+	dc.Spec.ReplicaStrategy = model.ReplicationStrategy_Single
+	dc.Spec.Strategy.Type = model.DeploymentStrategyTypeRecreate
+	container := &dc.Spec.Template.Spec.Containers[0]
+	container.Env = []k8.EnvVar{
+		{Name: "POSTGRESQL_USER", Value: "${POSTGRESQL_USER}"},
+		{Name: "POSTGRESQL_PASSWORD", Value: "${POSTGRESQL_PASSWORD}"},
+		{Name: "POSTGRESQL_DATABASE", Value: "${POSTGRESQL_DATABASE}"},
+	}
+	container.Resources = resources("250m", "512Mi", "", "1Gi")
+	mount(dc, "postgresql-data", "postgresql-data", "/var/lib/pgsql/data")
+	appTemp.DeploymentConfigs["postgresql"] = dc
+	appTemp.Services["postgresql"] = service("postgresql", "postgresql", "", 5432)
+	appTemp.PersistentVolumes["postgresql-data"] = claim("postgresql-data", "5Gi")
+	appTemp.Parameters = []*model.Parameter{
+		{Name: "POSTGRESQL_VERSION", Description: "the postgresql version of the centos/postgresql image", Value: "95", Required: true},
+		{Name: "POSTGRESQL_USER", Description: "the user the application connects as", Value: "app", Required: true},
+		{Name: "POSTGRESQL_PASSWORD", Description: "the password of the user", Generate: "expression", From: "[a-zA-Z0-9]{16}", Required: true},
+		{Name: "POSTGRESQL_DATABASE", Description: "the database created for the application", Value: "app", Required: true},
+	}
+	return &Starter{Name: "postgresql", Description: appTemp.Annotations["description"], Source: SOURCE_BUILTIN, Template: appTemp}
+}
+
+// mongodb runs a member of the replica set on every node, each with its own config and claim so members keep their
+// data and identity, and as a StatefulSet on kubernetes. The headless service gives every member a dns name to join
+// the set with
+func mongodb() *Starter {
+	appTemp := starterTemplate("mongodb", "mongodb replica set with a member and claim per node")
+	dc := deployment("mongodb-%d", "centos/mongodb-${MONGODB_VERSION}-centos7", k8.ContainerPort{Name: "mongodb", ContainerPort: 27017})
+	dc.Spec.Template.Name = "mongodb"
+	dc.Spec.Template.Labels = map[string]string{"name": "mongodb"}
+	dc.Labels = dc.Spec.Template.Labels
+	dc.Spec.Selector = map[string]string{"name": "mongodb"}
+	dc.Spec.DeploymentStrategy = model.DeploymentStrategy_PerNodeConfig
+	dc.Spec.ReplicaStrategy = model.ReplicationStrategy_Single
+	dc.Spec.WorkloadKind = model.WorkloadKind_StatefulSet
+	dc.Spec.Strategy.Type = model.DeploymentStrategyTypeRecreate
+	container := &dc.Spec.Template.Spec.Containers[0]
+	container.Name = "mongodb"
+	container.Args = []string{"run-mongod-replication"}
+	container.Env = []k8.EnvVar{
+		{Name: "MONGODB_USER", Value: "${MONGODB_USER}"},
+		{Name: "MONGODB_PASSWORD", Value: "${MONGODB_PASSWORD}"},
+		{Name: "MONGODB_DATABASE", Value: "${MONGODB_DATABASE}"},
+		{Name: "MONGODB_ADMIN_PASSWORD", Value: "${MONGODB_ADMIN_PASSWORD}"},
+		{Name: "MONGODB_REPLICA_NAME", Value: "${MONGODB_REPLICA_NAME}"},
+		{Name: "MONGODB_KEYFILE_VALUE", Value: "${MONGODB_KEYFILE_VALUE}"},
+		{Name: "MONGODB_SERVICE_NAME", Value: "mongodb"},
+	}
+	container.Resources = resources("250m", "512Mi", "", "1Gi")
+	mount(dc, "mongodb-data", "mongodb-data-%d", "/var/lib/mongodb/data")
+	appTemp.DeploymentConfigs["mongodb"] = dc
+	appTemp.Services["mongodb"] = service("mongodb", "mongodb", model.ServiceType_Headless, 27017)
+	appTemp.PersistentVolumes["mongodb-data"] = claim("mongodb-data-%d", "10Gi")
+	appTemp.Parameters = []*model.Parameter{
+		{Name: "MONGODB_VERSION", Description: "the mongodb version of the centos/mongodb image", Value: "32", Required: true},
+		{Name: "MONGODB_REPLICA_NAME", Description: "the name of the replica set", Value: "rs0", Required: true},
+		{Name: "MONGODB_USER", Description: "the user the application connects as", Value: "app", Required: true},
+		{Name: "MONGODB_PASSWORD", Description: "the password of the user", Generate: "expression", From: "[a-zA-Z0-9]{16}", Required: true},
+		{Name: "MONGODB_DATABASE", Description: "the database created for the application", Value: "app", Required: true},
+		{Name: "MONGODB_ADMIN_PASSWORD", Description: "the password of the admin user", Generate: "expression", From: "[a-zA-Z0-9]{16}", Required: true},
+		{Name: "MONGODB_KEYFILE_VALUE", Description: "the key the members of the set authenticate each other with", Generate: "expression", From: "[a-zA-Z0-9]{255}", Required: true},
+	}
+	return &Starter{Name: "mongodb", Description: appTemp.Annotations["description"], Source: SOURCE_BUILTIN, Template: appTemp}
+}
+
+func nginx() *Starter {
+	appTemp := starterTemplate("nginx", "nginx web server on every node behind a route")
+	dc := deployment("nginx", "centos/nginx-${NGINX_VERSION}-centos7", k8.ContainerPort{Name: "http", ContainerPort: 8080})
+	dc.Spec.ReplicaStrategy = model.ReplicationStrategy_EqualToNodes
+	dc.Spec.Strategy.Type = model.DeploymentStrategyTypeRolling
+	container := &dc.Spec.Template.Spec.Containers[0]
+	container.Resources = resources("50m", "64Mi", "", "128Mi")
+	container.ReadinessProbe = &k8.Probe{
+		Handler:             k8.Handler{HTTPGet: &k8.HTTPGetAction{Path: "/", Port: intstr.FromString("http")}},
+		InitialDelaySeconds: 2,
+		TimeoutSeconds:      1,
+	}
+	appTemp.DeploymentConfigs["nginx"] = dc
+	appTemp.Services["nginx"] = service("nginx", "nginx", "", 8080)
+	route := &model.Route{}
+	route.Kind = "Route"
+	route.APIVersion = "v1"
+	route.Name = "nginx"
+	route.Spec.Host = "${NGINX_HOST}"
+	route.Spec.To = k8.ObjectReference{Kind: "Service", Name: "nginx"}
+	appTemp.Routes["nginx"] = route
+	appTemp.Parameters = []*model.Parameter{
+		{Name: "NGINX_VERSION", Description: "the nginx version of the centos/nginx image", Value: "112", Required: true},
+		{Name: "NGINX_HOST", Description: "the host the route serves, the router picks one when empty"},
+	}
+	return &Starter{Name: "nginx", Description: appTemp.Annotations["description"], Source: SOURCE_BUILTIN, Template: appTemp}
+}
+
+func starterTemplate(name, description string) *model.ApplicationTemplate {
+	appTemp := model.NewApplicationTemplate(name)
+	appTemp.Annotations["description"] = description
+	return appTemp
+}
+
+// deployment is a deployment config running a single container named after it
+func deployment(name, image string, port k8.ContainerPort) *model.OSTDeploymentConfig {
+	dc := model.NewOstDeploymentConfig(name)
+	dc.Spec.Template.Spec.Containers = []k8.Container{{
+		Name:  name,
+		Image: image,
+		Ports: []k8.ContainerPort{port},
+	}}
+	return dc
+}
+
+// mount adds a claim volume to the deployment mounted into its first container
+func mount(dc *model.OSTDeploymentConfig, volume, claimName, path string) {
+	dc.Spec.Template.Spec.Volumes = append(dc.Spec.Template.Spec.Volumes, k8.Volume{
+		Name:         volume,
+		VolumeSource: k8.VolumeSource{PersistentVolumeClaim: &k8.PersistentVolumeClaimVolumeSource{ClaimName: claimName}},
+	})
+	container := &dc.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, k8.VolumeMount{Name: volume, MountPath: path})
+}
+
+func service(name, selects, serviceType string, port int32) *k8.Service {
+	s := model.NewService(name, map[string]string{"name": selects})
+	model.SetServiceType(s, serviceType)
+	s.Spec.Ports = append(s.Spec.Ports, k8.ServicePort{
+		Name:       name,
+		Port:       port,
+		TargetPort: intstr.FromInt(int(port)),
+		Protocol:   k8.ProtocolTCP,
+	})
+	return s
+}
+
+func claim(name, size string) *k8.PersistentVolumeClaim {
+	pvc := &k8.PersistentVolumeClaim{}
+	pvc.Kind = "PersistentVolumeClaim"
+	pvc.APIVersion = "v1"
+	pvc.Name = name
+	pvc.Spec.AccessModes = []k8.PersistentVolumeAccessMode{k8.ReadWriteOnce}
+	pvc.Spec.Resources.Requests = k8.ResourceList{k8.ResourceStorage: resource.MustParse(size)}
+	return pvc
+}
+
+// resources sets requests and limits, an empty value is left unset
+func resources(cpuRequest, memoryRequest, cpuLimit, memoryLimit string) k8.ResourceRequirements {
+	list := func(cpu, memory string) k8.ResourceList {
+		l := k8.ResourceList{}
+		if "" != cpu {
+			l[k8.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if "" != memory {
+			l[k8.ResourceMemory] = resource.MustParse(memory)
+		}
+		return l
+	}
+	return k8.ResourceRequirements{Requests: list(cpuRequest, memoryRequest), Limits: list(cpuLimit, memoryLimit)}
+}
@@ -0,0 +1,163 @@
+// Package catalog holds the starters new templates can be created from. The builtin starters are part of the binary
+// and more can be added as json templates in catalog directories, one starter per file named after the file
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/maleck13/templator/model"
+)
+
+// SOURCE_BUILTIN is the source of the starters that are part of the binary
+const SOURCE_BUILTIN = "builtin"
+
+// Dirs are the user catalog directories, searched in order after the builtin starters. A starter in a directory
+// replaces a builtin or earlier starter of the same name. It is set from the --catalog flag
+var Dirs = []string{DefaultDir()}
+
+// DefaultDir is the catalog directory used when none is given
+func DefaultDir() string {
+	home := os.Getenv("HOME")
+	if "" == home {
+		home = "."
+	}
+	return filepath.Join(home, ".templator", "catalog")
+}
+
+// Starter is a template new templates can be created from
+type Starter struct {
+	Name        string
+	Description string
+	// Source is SOURCE_BUILTIN or the file the starter was read from
+	Source   string
+	Template *model.ApplicationTemplate
+}
+
+// List returns every starter by name. Catalog directories that do not exist are skipped
+func List() ([]*Starter, error) {
+	byName := make(map[string]*Starter)
+	for _, s := range builtin() {
+		byName[s.Name] = s
+	}
+	for _, dir := range Dirs {
+		starters, err := readDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range starters {
+			byName[s.Name] = s
+		}
+	}
+	starters := make([]*Starter, 0, len(byName))
+	for _, s := range byName {
+		starters = append(starters, s)
+	}
+	sort.Sort(starterSorter(starters))
+	return starters, nil
+}
+
+// Get returns the named starter
+func Get(name string) (*Starter, error) {
+	starters, err := List()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range starters {
+		if s.Name == name {
+			return s, nil
+		}
+		names = append(names, s.Name)
+	}
+	return nil, fmt.Errorf("no starter named %s in the catalog expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Instantiate returns a new template called name from the starter. Objects named after the starter are renamed
+// after the new template the same way a rename does, as are env values that are the starter name as those are how
+// starters pass a service name to their containers
+func (s *Starter) Instantiate(name string) (*model.ApplicationTemplate, error) {
+	appTemp, err := s.Template.Copy()
+	if err != nil {
+		return nil, err
+	}
+	appTemp.ResourceVersion = ""
+	if nil == appTemp.Annotations {
+		appTemp.Annotations = make(map[string]string)
+	}
+	oldName := appTemp.Name
+	appTemp.Rename(name)
+	for _, dc := range appTemp.DeploymentConfigs {
+		if nil == dc.Spec.Template {
+			continue
+		}
+		for i := range dc.Spec.Template.Spec.Containers {
+			env := dc.Spec.Template.Spec.Containers[i].Env
+			for j := range env {
+				if env[j].Value == oldName {
+					env[j].Value = name
+				}
+			}
+		}
+	}
+	if err := model.ValidateTemplate(appTemp); err != nil {
+		return nil, fmt.Errorf("starter %s does not make a valid template %s", s.Name, err.Error())
+	}
+	return appTemp, nil
+}
+
+// readDir reads every .json file of dir as a starter
+func readDir(dir string) ([]*Starter, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var starters []*Starter
+	for _, f := range files {
+		if f.IsDir() || ".json" != filepath.Ext(f.Name()) {
+			continue
+		}
+		file := filepath.Join(dir, f.Name())
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		appTemp := &model.ApplicationTemplate{}
+		if err := json.Unmarshal(data, appTemp); err != nil {
+			return nil, fmt.Errorf("failed to read starter %s %s", file, err.Error())
+		}
+		name := strings.TrimSuffix(f.Name(), ".json")
+		if "" == appTemp.Name {
+			appTemp.Name = name
+		}
+		starters = append(starters, &Starter{
+			Name:        name,
+			Description: appTemp.Annotations["description"],
+			Source:      file,
+			Template:    appTemp,
+		})
+	}
+	return starters, nil
+}
+
+type starterSorter []*Starter
+
+func (ss starterSorter) Len() int {
+	return len(ss)
+}
+
+func (ss starterSorter) Swap(i, j int) {
+	ss[i], ss[j] = ss[j], ss[i]
+}
+
+func (ss starterSorter) Less(i, j int) bool {
+	return ss[i].Name < ss[j].Name
+}
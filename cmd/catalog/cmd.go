@@ -0,0 +1,93 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/maleck13/templator/catalog"
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/cmd/read"
+	"github.com/urfave/cli"
+)
+
+func CatalogCmd() cli.Command {
+	return cli.Command{
+		Name:  "catalog",
+		Usage: "browse the starters templates can be created from with create app_template <name> --from=<starter>",
+		Subcommands: []cli.Command{
+			ListCatalogCmd(),
+			ShowCatalogCmd(),
+		},
+	}
+}
+
+func ListCatalogCmd() cli.Command {
+	return cli.Command{
+		Name:  "list",
+		Usage: "list the builtin starters and those of the catalog directories",
+		Action: func(context *cli.Context) error {
+			if err := ListCatalogAction(); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func ShowCatalogCmd() cli.Command {
+	return cli.Command{
+		Name:         "show",
+		ArgsUsage:    "<starter>",
+		BashComplete: cmd.CompleteArgs(starterNames),
+		Usage:        "show <starter> -o json|yaml|go-template=...|jsonpath=...",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "output, o",
+				Usage: "-o json|yaml|go-template=...|jsonpath=... defaults to a summary table",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 1 {
+				return cli.NewExitError("expected one arg "+context.Command.ArgsUsage, 1)
+			}
+			if err := ShowCatalogAction(context.Args()[0], context.String("output")); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func starterNames(args []string) []string {
+	starters, err := catalog.List()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(starters))
+	for _, s := range starters {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func ListCatalogAction() error {
+	starters, err := catalog.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tDESCRIPTION")
+	for _, s := range starters {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Source, s.Description)
+	}
+	return w.Flush()
+}
+
+func ShowCatalogAction(name, output string) error {
+	starter, err := catalog.Get(name)
+	if err != nil {
+		return err
+	}
+	return read.PrintTemplate(os.Stdout, starter.Template, output)
+}
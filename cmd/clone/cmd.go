@@ -0,0 +1,90 @@
+package clone
+
+import (
+	"fmt"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+var (
+	flag_ToStore string
+)
+
+func CopyCmd() cli.Command {
+	return cli.Command{
+		Name:         "copy",
+		ArgsUsage:    "<src> <dst>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "copy <src> <dst> --to-store=../other/.templates.json",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:        "to-store",
+				Usage:       "--to-store=<file> writes the copy to another store file instead of the current one",
+				Destination: &flag_ToStore,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := CopyAction(context.Args()[0], context.Args()[1], flag_ToStore); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func RenameCmd() cli.Command {
+	return cli.Command{
+		Name:         "rename",
+		ArgsUsage:    "<old> <new>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "rename <old> <new>",
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := RenameAction(context.Args()[0], context.Args()[1]); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func CopyAction(src, dst, toStore string) error {
+	srcService := service.NewTemplateService("local")
+	dstService := srcService
+	if "" != toStore {
+		dstService = service.NewLocalTemplateService(toStore)
+	}
+	appTemp, err := srcService.GetTemplate(src)
+	if err != nil {
+		return err
+	}
+	if nil == appTemp {
+		return fmt.Errorf("no template named %s", src)
+	}
+	existing, err := dstService.GetTemplate(dst)
+	if err != nil {
+		return err
+	}
+	if nil != existing {
+		return fmt.Errorf("a template named %s already exists in %s", dst, dstService.Location)
+	}
+	copied, err := appTemp.Copy()
+	if err != nil {
+		return err
+	}
+	copied.Rename(dst)
+	copied.ResourceVersion = ""
+	return dstService.SaveTemplate(dst, copied)
+}
+
+func RenameAction(oldName, newName string) error {
+	templateService := service.NewTemplateService("local")
+	return templateService.RenameTemplate(oldName, newName)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strings"
 )
 
 func QuestionAndAnswer(q string, answer func(string)) error {
@@ -18,3 +19,16 @@ func QuestionAndAnswer(q string, answer func(string)) error {
 	return nil
 
 }
+
+// ParseKeyValues parses key=value pairs such as those given to --label
+func ParseKeyValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		keyVal := strings.SplitN(p, "=", 2)
+		if len(keyVal) != 2 || "" == keyVal[0] {
+			return nil, fmt.Errorf("expected key=value but got %s", p)
+		}
+		values[keyVal[0]] = keyVal[1]
+	}
+	return values, nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+// ArgCompleter returns the candidates for an argument given the arguments before it
+type ArgCompleter func(args []string) []string
+
+// CompleteArgs completes each positional argument with the completer at its position. A nil completer or a position
+// past the completers completes nothing
+func CompleteArgs(completers ...ArgCompleter) cli.BashCompleteFunc {
+	return func(context *cli.Context) {
+		args := context.Args()
+		if len(args) >= len(completers) || nil == completers[len(args)] {
+			return
+		}
+		for _, candidate := range completers[len(args)](args) {
+			fmt.Fprintln(context.App.Writer, candidate)
+		}
+	}
+}
+
+// TemplateNames completes the names of the templates in the configured store
+func TemplateNames(args []string) []string {
+	templates, err := service.NewTemplateService("local").ListTemplates()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ObjectNames completes the names of the objects of a kind in the template given as the first argument. Kind is one
+// of deployment, service, route, volume, parameter or profile
+func ObjectNames(kind string) ArgCompleter {
+	return func(args []string) []string {
+		if len(args) == 0 {
+			return nil
+		}
+		appTemp, err := service.NewTemplateService("local").GetTemplate(args[0])
+		if err != nil || nil == appTemp {
+			return nil
+		}
+		var names []string
+		switch kind {
+		case "deployment":
+			for name := range appTemp.DeploymentConfigs {
+				names = append(names, name)
+			}
+		case "service":
+			for name := range appTemp.Services {
+				names = append(names, name)
+			}
+		case "route":
+			for name := range appTemp.Routes {
+				names = append(names, name)
+			}
+		case "volume":
+			for name := range appTemp.PersistentVolumes {
+				names = append(names, name)
+			}
+		case "parameter":
+			for _, p := range appTemp.Parameters {
+				names = append(names, p.Name)
+			}
+		case "profile":
+			names = profileNames(appTemp)
+		}
+		sort.Strings(names)
+		return names
+	}
+}
+
+func profileNames(appTemp *model.ApplicationTemplate) []string {
+	names := make([]string, 0, len(appTemp.Profiles))
+	for name := range appTemp.Profiles {
+		names = append(names, name)
+	}
+	return names
+}
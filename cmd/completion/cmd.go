@@ -0,0 +1,75 @@
+package completion
+
+import (
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	BASH_COMPLETION = `_{{.}}_complete() {
+  local cur opts
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null)
+  COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+  return 0
+}
+complete -o default -F _{{.}}_complete {{.}}
+`
+	ZSH_COMPLETION = `#compdef {{.}}
+_{{.}}() {
+  local -a opts
+  opts=("${(@f)$(${words[@]:0:$((CURRENT-1))} --generate-bash-completion 2>/dev/null)}")
+  compadd -a opts
+}
+compdef _{{.}} {{.}}
+`
+	FISH_COMPLETION = `function __{{.}}_complete
+    set -l args (commandline -opc)
+    command $args --generate-bash-completion 2>/dev/null
+end
+complete -c {{.}} -f -a '(__{{.}}_complete)'
+`
+)
+
+var scripts = map[string]string{
+	"bash": BASH_COMPLETION,
+	"zsh":  ZSH_COMPLETION,
+	"fish": FISH_COMPLETION,
+}
+
+func CompletionCmd() cli.Command {
+	return cli.Command{
+		Name:      "completion",
+		ArgsUsage: "bash|zsh|fish",
+		Usage:     "completion bash|zsh|fish prints a completion script, e.g. source <(templator completion bash)",
+		BashComplete: func(context *cli.Context) {
+			if len(context.Args()) == 0 {
+				for _, shell := range []string{"bash", "zsh", "fish"} {
+					fmt.Fprintln(context.App.Writer, shell)
+				}
+			}
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 1 {
+				return cli.NewExitError("expected one arg "+context.Command.ArgsUsage, 1)
+			}
+			if err := CompletionAction(context.Args()[0], context.App.Name); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// CompletionAction prints the completion script of a shell for the named program
+func CompletionAction(shell, program string) error {
+	script, ok := scripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %s expected bash|zsh|fish", shell)
+	}
+	return template.Must(template.New(shell).Parse(script)).Execute(os.Stdout, program)
+}
@@ -0,0 +1,79 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+const defaultTargetCPU = 80
+
+func CreateAutoscalerCmd() cli.Command {
+	return cli.Command{
+		Name:         "autoscaler",
+		ArgsUsage:    "<template> <deployment>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames("deployment")),
+		Usage:        "autoscaler <template> <deployment> --min=2 --max=10 --cpu=80 scales the deployment between min and max replicas on cpu use",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "min",
+				Value: 1,
+				Usage: "--min=2 the fewest replicas to scale down to",
+			},
+			cli.IntFlag{
+				Name:  "max",
+				Usage: "--max=10 the most replicas to scale up to",
+			},
+			cli.IntFlag{
+				Name:  "cpu",
+				Value: defaultTargetCPU,
+				Usage: "--cpu=80 the average cpu use to scale at as a percentage of the cpu request",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if !context.IsSet("max") {
+				return cli.NewExitError("--max is required", 1)
+			}
+			autoscaler := &model.Autoscaler{
+				MinReplicas:                    int32(context.Int("min")),
+				MaxReplicas:                    int32(context.Int("max")),
+				TargetCPUUtilizationPercentage: int32(context.Int("cpu")),
+			}
+			if err := CreateAutoscalerAction(context.Args()[0], context.Args()[1], autoscaler); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// CreateAutoscalerAction sets the autoscaler of the deployment, replacing any it already has
+func CreateAutoscalerAction(temp, depName string, autoscaler *model.Autoscaler) error {
+	templateService := service.NewTemplateService("local")
+	return templateService.UpdateTemplate(temp, func(appTemp *model.ApplicationTemplate) error {
+		dc, ok := appTemp.DeploymentConfigs[depName]
+		if !ok {
+			return fmt.Errorf("no deployment named %s in template %s", depName, temp)
+		}
+		replaced := dc.Spec.Autoscaler != nil
+		dc.Spec.Autoscaler = autoscaler
+		if err := model.ValidateDeploymentConfig(dc); err != nil {
+			return err
+		}
+		if model.ReplicationStrategy_EqualToNodes == dc.Spec.ReplicaStrategy || model.ReplicationStrategy_Single == dc.Spec.ReplicaStrategy {
+			fmt.Printf("deployment %s has replicas %s which the autoscaler overrides when generated\n", depName, dc.Spec.ReplicaStrategy)
+		}
+		if replaced {
+			fmt.Println("updated autoscaler of deployment " + depName)
+			return nil
+		}
+		fmt.Println("created autoscaler for deployment " + depName)
+		return nil
+	})
+}
@@ -12,6 +12,8 @@ func CreateCmd() cli.Command {
 		Subcommands: []cli.Command{
 			CreateTemplateCmd(),
 			CreateDeploymentCmd(),
+			CreateServiceCmd(),
+			CreateAutoscalerCmd(),
 		},
 		Flags: []cli.Flag{
 			cli.StringFlag{
@@ -3,6 +3,7 @@ package create
 import (
 	"github.com/urfave/cli"
 	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/secret"
 	"github.com/maleck13/templator/service"
 	"log"
 	k8 "k8s.io/kubernetes/pkg/api/v1"
@@ -18,9 +19,10 @@ import (
 
 func CreateDeploymentCmd() cli.Command {
 	return cli.Command{
-		Name:      "deployment",
-		ArgsUsage: "<name> <template>",
-		Usage:     "deployment <name> <template>",
+		Name:         "deployment",
+		ArgsUsage:    "<name> <template>",
+		BashComplete: cmd.CompleteArgs(nil, cmd.TemplateNames),
+		Usage:        "deployment <name> <template>",
 		Action: func(context *cli.Context) error {
 			if len(context.Args()) != 2 {
 				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
@@ -30,29 +32,43 @@ func CreateDeploymentCmd() cli.Command {
 	}
 }
 
-func addServices(deploymentModel *model.OSTDeploymentConfig) (*k8.Service, error) {
-	//wrap in constructor
-	serviceTemp := &k8.Service{}
-	serviceTemp.APIVersion = "v1"
-	serviceTemp.Kind = "Service"
-	serviceTemp.Spec.Selector = make(map[string]string)
-	serviceTemp.Spec.Selector["name"] = deploymentModel.Name
-	serviceTemp.Spec.Ports = make([]k8.ServicePort, 0)
-	cmd.QuestionAndAnswer("Do you want to expose any services for this deployment : ", func(answer string) {
-		if "n" == answer {
+func addServices(deploymentModel *model.OSTDeploymentConfig) ([]*k8.Service, error) {
+	var (
+		services []*k8.Service
+		err      error
+	)
+	cmd.QuestionAndAnswer("Do you want to expose any services for this deployment (y/n) : ", func(answer string) {
+		if "n" == strings.ToLower(answer) {
+			return
+		}
+		var fromPorts bool
+		cmd.QuestionAndAnswer("Do you want to create the services from the container ports (y/n) : ", func(answer string) {
+			fromPorts = "y" == strings.ToLower(answer)
+		})
+		serviceType := askServiceType()
+		if fromPorts {
+			var perGroup bool
+			cmd.QuestionAndAnswer("one service for all ports or one per named port group e.g. admin-http,admin-metrics (all/group) : ", func(answer string) {
+				perGroup = "group" == strings.ToLower(answer)
+			})
+			services, err = model.ServicesFromPorts(deploymentModel, serviceType, perGroup)
+			return
+		}
+		serviceTemp := model.NewService("", deploymentModel.Spec.Template.Labels)
+		if err = model.SetServiceType(serviceTemp, serviceType); err != nil {
 			return
 		}
-
 		cmd.QuestionAndAnswer("name the service : ", func(name string) {
 			serviceTemp.ObjectMeta.Name = name
 		})
+		protocol := askProtocol()
 
 		cmd.QuestionAndAnswer("which ports do you want to expose (8080,3000) : ", func(answer string) {
 			ports := strings.Split(answer, ",")
 			for i, p := range ports {
 				port := k8.ServicePort{}
 				port.Name = fmt.Sprintf("%s-port-%d", serviceTemp.Name, i)
-				port.Protocol = "TCP"
+				port.Protocol = protocol
 				pN, _ := strconv.ParseInt(p, 10, 32) //fix ignored error
 				port.Port = int32(pN)
 				cmd.QuestionAndAnswer("what is the target port for "+p+" :", func(answer string) {
@@ -63,9 +79,55 @@ func addServices(deploymentModel *model.OSTDeploymentConfig) (*k8.Service, error
 			}
 
 		})
+		services = append(services, serviceTemp)
+	})
+	return services, err
+}
 
+func askServiceType() string {
+	for {
+		var serviceType string
+		cmd.QuestionAndAnswer("what type of service (ClusterIP/NodePort/LoadBalancer/headless) [ClusterIP] : ", func(answer string) {
+			serviceType = strings.TrimSpace(answer)
+		})
+		if err := model.SetServiceType(&k8.Service{}, serviceType); err != nil {
+			fmt.Println(err.Error())
+			continue
+		}
+		return serviceType
+	}
+}
+
+func askProtocol() k8.Protocol {
+	protocol := k8.ProtocolTCP
+	cmd.QuestionAndAnswer("which protocol (TCP/UDP) [TCP] : ", func(answer string) {
+		if "udp" == strings.ToLower(strings.TrimSpace(answer)) {
+			protocol = k8.ProtocolUDP
+		}
 	})
-	return serviceTemp, nil
+	return protocol
+}
+
+// parseContainerPort parses [name:]port[/protocol] e.g. http:8080 or dns:53/udp
+func parseContainerPort(p string) (k8.ContainerPort, error) {
+	port := k8.ContainerPort{Protocol: k8.ProtocolTCP}
+	p = strings.TrimSpace(p)
+	if i := strings.Index(p, "/"); i != -1 {
+		if "udp" == strings.ToLower(p[i+1:]) {
+			port.Protocol = k8.ProtocolUDP
+		}
+		p = p[:i]
+	}
+	if i := strings.Index(p, ":"); i != -1 {
+		port.Name = p[:i]
+		p = p[i+1:]
+	}
+	n, err := strconv.ParseInt(p, 10, 32)
+	if err != nil {
+		return port, err
+	}
+	port.ContainerPort = int32(n)
+	return port, nil
 }
 
 func addContainers(deploymentModel *model.OSTDeploymentConfig) {
@@ -86,17 +148,14 @@ func addContainers(deploymentModel *model.OSTDeploymentConfig) {
 	cmd.QuestionAndAnswer("What image do you want to use :", func(answer string) {
 		container.Image = answer
 	})
-	cmd.QuestionAndAnswer("What ports do you want to expose (8080,8443) :", func(answer string) {
+	cmd.QuestionAndAnswer("What ports do you want to expose ([name:]port[/udp] e.g. http:8080,dns:53/udp) :", func(answer string) {
 		ports := strings.Split(answer, ",")
 		for _, p := range ports {
-			i, err := strconv.ParseInt(p, 10, 32)
+			port, err := parseContainerPort(p)
 			if err != nil {
-				log.Fatal("could not parse int ", err)
+				log.Fatal("could not parse port ", err)
 			}
-			container.Ports = append(container.Ports, k8.ContainerPort{
-				ContainerPort: int32(i),
-				Protocol:      "TCP", // default for now
-			})
+			container.Ports = append(container.Ports, port)
 		}
 	})
 	cmd.QuestionAndAnswer("Do you need to set resource limits?:", func(answer string) {
@@ -123,16 +182,12 @@ func addContainers(deploymentModel *model.OSTDeploymentConfig) {
 		if "" == answer {
 			return
 		}
-		envs := strings.Split(",", answer)
-		fmt.Println(envs)
-
-		for _, e := range envs {
-			keyVal := strings.Split(",", e) //handle out of range prob
-			container.Env = append(container.Env, k8.EnvVar{
-				Name:  keyVal[0],
-				Value: keyVal[1],
-			})
-		}
+		container.Env = append(container.Env, parseEnvVars(answer)...)
+		cmd.QuestionAndAnswer("Which of these env vars are sensitive and should be encrypted? (MY_ENV_VAR,MY_ENV_TWO)", func(answer string) {
+			if err := encryptEnvVars(container.Env, answer); err != nil {
+				log.Fatal("could not encrypt env vars ", err)
+			}
+		})
 	})
 	cmd.QuestionAndAnswer("Want to add another container ? (y/n) ", func(answer string) {
 		if "y" == answer {
@@ -150,29 +205,63 @@ func CreateDeploymentAction(name, temp string) error {
 	templateServ := service.NewTemplateService("local")
 
 	addContainers(deploymentModel)
-	serviceTemp, err := addServices(deploymentModel)
+	services, err := addServices(deploymentModel)
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	cmd.QuestionAndAnswer("what kind of upgrage strategy do you want to use (rolling/recreate) :", func(answer string) {
-		if "rolling" == strings.ToLower(answer) {
-			deploymentModel.Spec.Strategy = model.DeploymentStrategy{
-				Type: "Rolling",
-				RollingParams: &model.RollingDeploymentStrategyParams{ //prob need to prompt for these
-					UpdatePeriodSeconds: &[]int64{1}[0], //bit crap but it want a pointer rather than value.
-					IntervalSeconds:     &[]int64{1}[0],
-					TimeoutSeconds:      &[]int64{300}[0],
-				},
+	addStrategy(deploymentModel)
+
+	//the deployment and its services are added together so nothing is saved when any of them already exists
+	err = templateServ.UpdateTemplate(temp, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.DeploymentConfigs[name]; ok {
+			return fmt.Errorf("a deployment named %s already exists in template %s, delete it first to recreate it", name, temp)
+		}
+		for _, s := range services {
+			if _, ok := appTemp.Services[s.Name]; ok {
+				return fmt.Errorf("a service named %s already exists in template %s, delete it first to recreate it", s.Name, temp)
 			}
 		}
+		if nil == appTemp.DeploymentConfigs {
+			appTemp.DeploymentConfigs = make(map[string]*model.OSTDeploymentConfig)
+		}
+		if nil == appTemp.Services {
+			appTemp.Services = make(map[string]*k8.Service)
+		}
+		appTemp.DeploymentConfigs[name] = deploymentModel
+		for _, s := range services {
+			appTemp.Services[s.Name] = s
+		}
+		return nil
 	})
-
-	if err := templateServ.SaveDeployment(temp, name, deploymentModel); err != nil {
+	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
-	if err := templateServ.SaveService(temp, name, serviceTemp); err != nil {
-		return cli.NewExitError(err.Error(), 1)
+	return nil
+}
+
+// encryptEnvVars encrypts the values of the comma separated env var names with the local key
+func encryptEnvVars(env []k8.EnvVar, names string) error {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if "" == name {
+			continue
+		}
+		found := false
+		for i := range env {
+			if env[i].Name != name || secret.IsEncrypted(env[i].Value) {
+				continue
+			}
+			value, err := secret.EncryptValue(env[i].Value)
+			if err != nil {
+				return err
+			}
+			env[i].Value = value
+			found = true
+		}
+		if !found {
+			fmt.Printf("no env var named %s to encrypt\n", name)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,71 @@
+package create
+
+import (
+	"fmt"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+func CreateServiceCmd() cli.Command {
+	return cli.Command{
+		Name:         "service",
+		ArgsUsage:    "<template> <deployment>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames("deployment")),
+		Usage:        "service <template> <deployment> --type=NodePort --per-port-group creates services from the deployment's container ports",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "type",
+				Value: "ClusterIP",
+				Usage: "--type=ClusterIP|NodePort|LoadBalancer|headless",
+			},
+			cli.BoolFlag{
+				Name:  "per-port-group",
+				Usage: "--per-port-group creates a service per named port group (admin-http,admin-metrics are in group admin)",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := CreateServiceAction(context.Args()[0], context.Args()[1], context.String("type"), context.Bool("per-port-group")); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// CreateServiceAction adds the services exposing the deployment's ports, refusing to replace a service of the same name
+func CreateServiceAction(temp, depName, serviceType string, perPortGroup bool) error {
+	templateService := service.NewTemplateService("local")
+	return templateService.UpdateTemplate(temp, func(appTemp *model.ApplicationTemplate) error {
+		dc, ok := appTemp.DeploymentConfigs[depName]
+		if !ok {
+			return fmt.Errorf("no deployment named %s in template %s", depName, temp)
+		}
+		services, err := model.ServicesFromPorts(dc, serviceType, perPortGroup)
+		if err != nil {
+			return err
+		}
+		if len(services) == 0 {
+			return fmt.Errorf("deployment %s does not expose any container ports", depName)
+		}
+		for _, s := range services {
+			if _, ok := appTemp.Services[s.Name]; ok {
+				return fmt.Errorf("a service named %s already exists in template %s, delete it first to recreate it", s.Name, temp)
+			}
+		}
+		if appTemp.Services == nil {
+			appTemp.Services = map[string]*k8.Service{}
+		}
+		for _, s := range services {
+			appTemp.Services[s.Name] = s
+			fmt.Println("created service " + s.Name)
+		}
+		return nil
+	})
+}
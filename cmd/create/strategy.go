@@ -0,0 +1,223 @@
+package create
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+const (
+	defaultUpdatePeriodSeconds = 1
+	defaultIntervalSeconds     = 1
+	defaultTimeoutSeconds      = 300
+)
+
+func addStrategy(deploymentModel *model.OSTDeploymentConfig) {
+	cmd.QuestionAndAnswer("what kind of upgrade strategy do you want to use (rolling/recreate) : ", func(answer string) {
+		switch strings.ToLower(answer) {
+		case "rolling":
+			deploymentModel.Spec.Strategy = model.DeploymentStrategy{
+				Type:          model.DeploymentStrategyTypeRolling,
+				RollingParams: rollingParams(deploymentModel),
+			}
+		case "recreate":
+			deploymentModel.Spec.Strategy = model.DeploymentStrategy{
+				Type:           model.DeploymentStrategyTypeRecreate,
+				RecreateParams: recreateParams(deploymentModel),
+			}
+		}
+	})
+}
+
+func rollingParams(deploymentModel *model.OSTDeploymentConfig) *model.RollingDeploymentStrategyParams {
+	params := &model.RollingDeploymentStrategyParams{}
+	params.UpdatePeriodSeconds = askSeconds("seconds to wait between pod updates", defaultUpdatePeriodSeconds)
+	params.IntervalSeconds = askSeconds("seconds to wait between polling the deployment status", defaultIntervalSeconds)
+	params.TimeoutSeconds = askSeconds("seconds to wait for updates before giving up", defaultTimeoutSeconds)
+	params.MaxUnavailable = askIntOrPercent("max unavailable pods during the update (2 or 25%)")
+	params.MaxSurge = askIntOrPercent("max pods above the desired count during the update (2 or 25%)")
+	params.Pre = addLifecycleHook("pre", deploymentModel, true)
+	params.Post = addLifecycleHook("post", deploymentModel, false)
+	return params
+}
+
+func recreateParams(deploymentModel *model.OSTDeploymentConfig) *model.RecreateDeploymentStrategyParams {
+	params := &model.RecreateDeploymentStrategyParams{}
+	params.TimeoutSeconds = askSeconds("seconds to wait for updates before giving up", defaultTimeoutSeconds)
+	params.Pre = addLifecycleHook("pre", deploymentModel, true)
+	params.Mid = addLifecycleHook("mid", deploymentModel, true)
+	params.Post = addLifecycleHook("post", deploymentModel, true)
+	return params
+}
+
+// addLifecycleHook prompts for a single hook. The rolling post hook cannot abort so allowAbort is false for it
+func addLifecycleHook(phase string, deploymentModel *model.OSTDeploymentConfig, allowAbort bool) *model.LifecycleHook {
+	var hook *model.LifecycleHook
+	cmd.QuestionAndAnswer(fmt.Sprintf("Do you want a %s lifecycle hook (y/n) : ", phase), func(answer string) {
+		if "y" != strings.ToLower(answer) {
+			return
+		}
+		hook = &model.LifecycleHook{}
+		cmd.QuestionAndAnswer("what kind of hook (exec/tag) : ", func(answer string) {
+			if "tag" == strings.ToLower(answer) {
+				hook.TagImages = append(hook.TagImages, tagImageHook(deploymentModel))
+				return
+			}
+			hook.ExecNewPod = execNewPodHook(deploymentModel)
+		})
+		hook.FailurePolicy = askFailurePolicy(allowAbort)
+	})
+	return hook
+}
+
+func execNewPodHook(deploymentModel *model.OSTDeploymentConfig) *model.ExecNewPodHook {
+	execHook := &model.ExecNewPodHook{}
+	cmd.QuestionAndAnswer("what command should the hook run (./migrate.sh --up) : ", func(answer string) {
+		execHook.Command = strings.Fields(answer)
+	})
+	execHook.ContainerName = askContainerName(deploymentModel)
+	cmd.QuestionAndAnswer("Any env vars for the hook? (MY_ENV_VAR:MY_VALUE,MY_ENV_TWO:MY_VAL_TWO) : ", func(answer string) {
+		execHook.Env = parseEnvVars(answer)
+	})
+	cmd.QuestionAndAnswer("Which pod volumes should be copied to the hook pod (data,config) : ", func(answer string) {
+		for _, v := range strings.Split(answer, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				execHook.Volumes = append(execHook.Volumes, v)
+			}
+		}
+	})
+	return execHook
+}
+
+func tagImageHook(deploymentModel *model.OSTDeploymentConfig) model.TagImageHook {
+	tagHook := model.TagImageHook{}
+	tagHook.ContainerName = askContainerName(deploymentModel)
+	cmd.QuestionAndAnswer("which image stream tag should the image be tagged to (myapp:deployed) : ", func(answer string) {
+		tagHook.To = k8.ObjectReference{Kind: "ImageStreamTag", Name: strings.TrimSpace(answer)}
+	})
+	return tagHook
+}
+
+func askContainerName(deploymentModel *model.OSTDeploymentConfig) string {
+	containers := deploymentModel.Spec.Template.Spec.Containers
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	var name string
+	cmd.QuestionAndAnswer(fmt.Sprintf("which container should the hook use (%s) : ", strings.Join(names, ",")), func(answer string) {
+		name = strings.TrimSpace(answer)
+	})
+	if "" == name && len(names) > 0 {
+		return names[0]
+	}
+	for _, n := range names {
+		if n == name {
+			return name
+		}
+	}
+	fmt.Printf("container %s is not part of this deployment, using it anyway\n", name)
+	return name
+}
+
+func askFailurePolicy(allowAbort bool) model.LifecycleHookFailurePolicy {
+	policies := []model.LifecycleHookFailurePolicy{model.LifecycleHookFailurePolicyRetry, model.LifecycleHookFailurePolicyIgnore}
+	if allowAbort {
+		policies = append(policies, model.LifecycleHookFailurePolicyAbort)
+	}
+	options := make([]string, 0, len(policies))
+	for _, p := range policies {
+		options = append(options, string(p))
+	}
+	policy := model.LifecycleHookFailurePolicyIgnore
+	for {
+		var valid = true
+		cmd.QuestionAndAnswer(fmt.Sprintf("what should happen if the hook fails (%s) [%s] : ", strings.Join(options, "/"), policy), func(answer string) {
+			if "" == answer {
+				return
+			}
+			valid = false
+			for _, p := range policies {
+				if strings.ToLower(string(p)) == strings.ToLower(answer) {
+					policy = p
+					valid = true
+				}
+			}
+		})
+		if valid {
+			return policy
+		}
+		fmt.Println("unsupported failure policy " + strings.Join(options, "/"))
+	}
+}
+
+func askSeconds(q string, def int64) *int64 {
+	value := def
+	for {
+		var err error
+		cmd.QuestionAndAnswer(fmt.Sprintf("%s [%d] : ", q, def), func(answer string) {
+			if "" == answer {
+				return
+			}
+			value, err = strconv.ParseInt(answer, 10, 64)
+		})
+		if err == nil {
+			return &value
+		}
+		fmt.Println("could not parse seconds ", err)
+	}
+}
+
+// askIntOrPercent returns nil when nothing is entered so that the server side default is used
+func askIntOrPercent(q string) *intstr.IntOrString {
+	for {
+		var (
+			value *intstr.IntOrString
+			err   error
+		)
+		cmd.QuestionAndAnswer(q+" : ", func(answer string) {
+			answer = strings.TrimSpace(answer)
+			if "" == answer {
+				return
+			}
+			if strings.HasSuffix(answer, "%") {
+				if _, err = strconv.Atoi(strings.TrimSuffix(answer, "%")); err == nil {
+					v := intstr.FromString(answer)
+					value = &v
+				}
+				return
+			}
+			var i int
+			if i, err = strconv.Atoi(answer); err == nil {
+				v := intstr.FromInt(i)
+				value = &v
+			}
+		})
+		if err == nil {
+			return value
+		}
+		fmt.Println("expected a number or a percentage ", err)
+	}
+}
+
+// parseEnvVars parses MY_ENV_VAR:MY_VALUE,MY_ENV_TWO:MY_VAL_TWO
+func parseEnvVars(answer string) []k8.EnvVar {
+	var envs []k8.EnvVar
+	if "" == strings.TrimSpace(answer) {
+		return envs
+	}
+	for _, e := range strings.Split(answer, ",") {
+		keyVal := strings.SplitN(e, ":", 2)
+		env := k8.EnvVar{Name: strings.TrimSpace(keyVal[0])}
+		if len(keyVal) == 2 {
+			env.Value = keyVal[1]
+		}
+		envs = append(envs, env)
+	}
+	return envs
+}
@@ -2,6 +2,8 @@ package create
 
 import (
 	"github.com/urfave/cli"
+	"github.com/maleck13/templator/catalog"
+	"github.com/maleck13/templator/cmd"
 	"github.com/maleck13/templator/model"
 	"github.com/maleck13/templator/service"
 )
@@ -10,12 +12,34 @@ func CreateTemplateCmd() cli.Command {
 	return cli.Command{
 		Name:      "app_template",
 		ArgsUsage: "<name> --target=[openshift,kubernetes]",
-		Usage:     "<name> --target=openshift",
+		Usage:     "<name> --target=openshift --label=app=myapp --annotation=owner=me --from=redis",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "from",
+				Usage: "--from=<starter> starts the template from a catalog starter, see catalog list",
+			},
+			cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "--label=team=payments a label added to every generated object, can be repeated",
+			},
+			cli.StringSliceFlag{
+				Name:  "annotation",
+				Usage: "--annotation=owner=me an annotation added to every generated object, can be repeated",
+			},
+		},
 		Action: func(context *cli.Context) error {
 			if len(context.Args()) != 1 {
 				return cli.NewExitError("expected one arg "+context.Command.Usage, 1)
 			}
-			if err := CreateTemplateAction(context.Args()[0], flag_Target); err != nil {
+			labels, err := cmd.ParseKeyValues(context.StringSlice("label"))
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			annotations, err := cmd.ParseKeyValues(context.StringSlice("annotation"))
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			if err := CreateTemplateAction(context.Args()[0], flag_Target, context.String("from"), labels, annotations); err != nil {
 				return cli.NewExitError(err.Error(), 1)
 			}
 			return nil
@@ -23,8 +47,23 @@ func CreateTemplateCmd() cli.Command {
 	}
 }
 
-func CreateTemplateAction(name, target string) error {
+func CreateTemplateAction(name, target, from string, labels, annotations map[string]string) error {
 	templateService := service.NewTemplateService("local")
 	template := model.NewApplicationTemplate(name)
+	if "" != from {
+		starter, err := catalog.Get(from)
+		if err != nil {
+			return err
+		}
+		if template, err = starter.Instantiate(name); err != nil {
+			return err
+		}
+	}
+	if len(labels) > 0 {
+		template.ObjectLabels = labels
+	}
+	if len(annotations) > 0 {
+		template.ObjectAnnotations = annotations
+	}
 	return templateService.SaveTemplate(name, template)
 }
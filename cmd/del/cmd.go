@@ -7,6 +7,11 @@ func DeleteCmd() cli.Command {
 		Name: "delete",
 		Subcommands: []cli.Command{
 			DeleteTemplateCmd(),
+			DeleteDeploymentCmd(),
+			DeleteServiceCmd(),
+			DeleteRouteCmd(),
+			DeleteVolumeCmd(),
+			DeleteParameterCmd(),
 		},
 	}
 }
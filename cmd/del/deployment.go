@@ -0,0 +1,72 @@
+package del
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+var (
+	flag_Force bool
+)
+
+func DeleteDeploymentCmd() cli.Command {
+	return cli.Command{
+		Name:         "deployment",
+		ArgsUsage:    "<template> <name>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames("deployment")),
+		Usage:        "<template> <name> --force",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:        "force",
+				Usage:       "--force removes the services that select only this deployment and their routes without asking",
+				Destination: &flag_Force,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := DeleteDeploymentAction(context.Args()[0], context.Args()[1], flag_Force); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func DeleteDeploymentAction(temp, name string, force bool) error {
+	templateService := service.NewTemplateService("local")
+	appTemp, err := templateService.GetTemplate(temp)
+	if err != nil {
+		return err
+	}
+	if nil == appTemp {
+		return fmt.Errorf("no template named %s", temp)
+	}
+	services, routes := appTemp.DeploymentDependants(name)
+	if len(services) == 0 {
+		return templateService.DeleteDeployment(temp, name)
+	}
+	cascade := force
+	if !force {
+		q := fmt.Sprintf("deployment %s is the only one selected by services (%s)", name, strings.Join(services, ","))
+		if len(routes) > 0 {
+			q += fmt.Sprintf(" which routes (%s) point at", strings.Join(routes, ","))
+		}
+		q += fmt.Sprintf(", delete services (%s)", strings.Join(services, ","))
+		if len(routes) > 0 {
+			q += fmt.Sprintf(" and routes (%s)", strings.Join(routes, ","))
+		}
+		cmd.QuestionAndAnswer(q+" too? (y/n) : ", func(answer string) {
+			cascade = "y" == strings.ToLower(answer)
+		})
+	}
+	if cascade {
+		return templateService.DeleteDeploymentAndDependants(temp, name)
+	}
+	return templateService.DeleteDeployment(temp, name)
+}
@@ -0,0 +1,50 @@
+package del
+
+import (
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+func DeleteServiceCmd() cli.Command {
+	return deleteObjectCmd("service", func(temp, name string) error {
+		return service.NewTemplateService("local").DeleteService(temp, name)
+	})
+}
+
+func DeleteRouteCmd() cli.Command {
+	return deleteObjectCmd("route", func(temp, name string) error {
+		return service.NewTemplateService("local").DeleteRoute(temp, name)
+	})
+}
+
+func DeleteVolumeCmd() cli.Command {
+	return deleteObjectCmd("volume", func(temp, name string) error {
+		return service.NewTemplateService("local").DeleteVolume(temp, name)
+	})
+}
+
+func DeleteParameterCmd() cli.Command {
+	return deleteObjectCmd("parameter", func(temp, name string) error {
+		return service.NewTemplateService("local").DeleteParameter(temp, name)
+	})
+}
+
+// deleteObjectCmd builds a "<kind> <template> <name>" command that removes a single object from a stored template
+func deleteObjectCmd(kind string, deleteAction func(temp, name string) error) cli.Command {
+	return cli.Command{
+		Name:         kind,
+		ArgsUsage:    "<template> <name>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames(kind)),
+		Usage:        "<template> <name>",
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := deleteAction(context.Args()[0], context.Args()[1]); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
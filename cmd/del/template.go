@@ -2,14 +2,16 @@ package del
 
 import (
 	"github.com/urfave/cli"
+	"github.com/maleck13/templator/cmd"
 	"github.com/maleck13/templator/service"
 )
 
 func DeleteTemplateCmd() cli.Command {
 	return cli.Command{
-		Name:      "app_template",
-		ArgsUsage: "<name>",
-		Usage:     "<name>",
+		Name:         "app_template",
+		ArgsUsage:    "<name>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "<name>",
 		Action: func(context *cli.Context) error {
 			if len(context.Args()) != 1 {
 				return cli.NewExitError("expected one arg "+context.Command.Usage, 1)
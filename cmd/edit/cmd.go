@@ -0,0 +1,326 @@
+package edit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/secret"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+const (
+	DEFAULT_EDITOR = "vi"
+	EDIT_HEADER    = `# Please edit the object below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file will be
+# reopened with the relevant failures, saving it again unchanged abandons the edit.
+# Encrypted values are shown as ****** and keep their stored value unless replaced.
+#
+`
+)
+
+func EditCmd() cli.Command {
+	return cli.Command{
+		Name:         "edit",
+		ArgsUsage:    "<template> [deployment|service|route <name>]",
+		BashComplete: editComplete,
+		Usage:        "edit <template> [deployment|service|route <name>] opens the template or one of its objects in $EDITOR as yaml",
+		Action: func(context *cli.Context) error {
+			args := context.Args()
+			if len(args) != 1 && len(args) != 3 {
+				return cli.NewExitError("expected one or three args "+context.Command.ArgsUsage, 1)
+			}
+			var kind, name string
+			if len(args) == 3 {
+				kind, name = args[1], args[2]
+			}
+			if err := EditAction(args[0], kind, name); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func editComplete(context *cli.Context) {
+	args := context.Args()
+	var candidates []string
+	switch len(args) {
+	case 0:
+		candidates = cmd.TemplateNames(args)
+	case 1:
+		candidates = []string{"deployment", "service", "route"}
+	case 2:
+		candidates = cmd.ObjectNames(args[1])(args)
+	}
+	for _, c := range candidates {
+		fmt.Fprintln(context.App.Writer, c)
+	}
+}
+
+// editable is a stored object being edited. decode turns the edited json into a validated object and store puts
+// it back into the template
+type editable struct {
+	object interface{}
+	decode func(data []byte) (interface{}, error)
+	store  func(appTemp *model.ApplicationTemplate, obj interface{}) error
+}
+
+// EditAction opens the template, or the object of kind with name in it, in the editor until it is saved without
+// errors or the edit is abandoned. Encrypted values are shown as MASK and keep their stored value unless replaced
+func EditAction(templateName, kind, name string) error {
+	templateService := service.NewTemplateService("local")
+	appTemp, err := templateService.GetTemplate(templateName)
+	if err != nil {
+		return err
+	}
+	if nil == appTemp {
+		return fmt.Errorf("no template named %s", templateName)
+	}
+	masked, err := appTemp.Copy()
+	if err != nil {
+		return err
+	}
+	//encrypted values are never written to the edit file in plain text
+	secret.MaskTemplate(masked)
+	target, err := editTarget(masked, kind, name)
+	if err != nil {
+		return err
+	}
+	original, err := yaml.Marshal(target.object)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile("", "templator-edit-")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	path := file.Name() + ".yaml"
+	if err := os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	content := append([]byte(EDIT_HEADER), original...)
+	var failed []byte
+	for {
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			return err
+		}
+		if err := openEditor(path); err != nil {
+			return err
+		}
+		edited, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		edited = stripComments(edited)
+		if len(bytes.TrimSpace(edited)) == 0 {
+			fmt.Println("Edit cancelled, the file was empty")
+			return nil
+		}
+		if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+			fmt.Println("Edit cancelled, no changes made")
+			return nil
+		}
+		if failed != nil && bytes.Equal(edited, failed) {
+			return fmt.Errorf("edit cancelled, the file was saved again with the same errors, your changes are kept in %s", keepEdit(edited))
+		}
+		obj, err := decodeEdited(edited, target.decode)
+		if err == nil {
+			err = templateService.UpdateTemplate(templateName, func(stored *model.ApplicationTemplate) error {
+				if stored.ResourceVersion != appTemp.ResourceVersion {
+					return &service.ConflictError{Name: templateName, Expected: appTemp.ResourceVersion, Actual: stored.ResourceVersion}
+				}
+				if err := target.store(stored, obj); err != nil {
+					return err
+				}
+				return secret.SealTemplate(appTemp, stored)
+			})
+			if err == nil {
+				fmt.Printf("saved %s\n", editDescription(templateName, kind, name))
+				return nil
+			}
+			if service.IsConflict(err) {
+				return fmt.Errorf("%s your changes are kept in %s", err.Error(), keepEdit(edited))
+			}
+		}
+		failed = edited
+		content = append([]byte(EDIT_HEADER+errorComments(err)), edited...)
+	}
+}
+
+func editTarget(appTemp *model.ApplicationTemplate, kind, name string) (*editable, error) {
+	switch kind {
+	case "":
+		return &editable{
+			object: appTemp,
+			decode: func(data []byte) (interface{}, error) {
+				edited := &model.ApplicationTemplate{}
+				if err := json.Unmarshal(data, edited); err != nil {
+					return nil, err
+				}
+				if edited.Name != appTemp.Name {
+					return nil, model.ValidationError{{Field: "metadata.name", Message: "cannot be changed, use rename"}}
+				}
+				return edited, model.ValidateTemplate(edited)
+			},
+			store: func(stored *model.ApplicationTemplate, obj interface{}) error {
+				edited := obj.(*model.ApplicationTemplate)
+				edited.ResourceVersion = stored.ResourceVersion
+				*stored = *edited
+				return nil
+			},
+		}, nil
+	case "deployment":
+		dc, ok := appTemp.DeploymentConfigs[name]
+		if !ok {
+			return nil, fmt.Errorf("no deployment named %s in template %s", name, appTemp.Name)
+		}
+		return &editable{
+			object: dc,
+			decode: func(data []byte) (interface{}, error) {
+				edited := &model.OSTDeploymentConfig{}
+				if err := json.Unmarshal(data, edited); err != nil {
+					return nil, err
+				}
+				if edited.Name != dc.Name {
+					return nil, model.ValidationError{{Field: "metadata.name", Message: "cannot be changed"}}
+				}
+				return edited, model.ValidateDeploymentConfig(edited)
+			},
+			store: func(stored *model.ApplicationTemplate, obj interface{}) error {
+				stored.DeploymentConfigs[name] = obj.(*model.OSTDeploymentConfig)
+				return nil
+			},
+		}, nil
+	case "service":
+		s, ok := appTemp.Services[name]
+		if !ok {
+			return nil, fmt.Errorf("no service named %s in template %s", name, appTemp.Name)
+		}
+		return &editable{
+			object: s,
+			decode: func(data []byte) (interface{}, error) {
+				edited := &k8.Service{}
+				if err := json.Unmarshal(data, edited); err != nil {
+					return nil, err
+				}
+				if edited.Name != s.Name {
+					return nil, model.ValidationError{{Field: "metadata.name", Message: "cannot be changed"}}
+				}
+				return edited, model.ValidateService(edited)
+			},
+			store: func(stored *model.ApplicationTemplate, obj interface{}) error {
+				stored.Services[name] = obj.(*k8.Service)
+				return nil
+			},
+		}, nil
+	case "route":
+		r, ok := appTemp.Routes[name]
+		if !ok {
+			return nil, fmt.Errorf("no route named %s in template %s", name, appTemp.Name)
+		}
+		return &editable{
+			object: r,
+			decode: func(data []byte) (interface{}, error) {
+				edited := &model.Route{}
+				if err := json.Unmarshal(data, edited); err != nil {
+					return nil, err
+				}
+				if edited.Name != r.Name {
+					return nil, model.ValidationError{{Field: "metadata.name", Message: "cannot be changed"}}
+				}
+				return edited, model.ValidateRoute(edited)
+			},
+			store: func(stored *model.ApplicationTemplate, obj interface{}) error {
+				stored.Routes[name] = obj.(*model.Route)
+				return nil
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("cannot edit %s expected deployment, service or route", kind)
+}
+
+func decodeEdited(edited []byte, decode func([]byte) (interface{}, error)) (interface{}, error) {
+	data, err := yaml.YAMLToJSON(edited)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// openEditor runs $VISUAL or $EDITOR on path attached to the terminal
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if "" == editor {
+		editor = os.Getenv("EDITOR")
+	}
+	if "" == editor {
+		editor = DEFAULT_EDITOR
+	}
+	args := append(strings.Fields(editor), path)
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %s failed %s", editor, err.Error())
+	}
+	return nil
+}
+
+func stripComments(data []byte) []byte {
+	var out bytes.Buffer
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		out.WriteString(line)
+	}
+	return out.Bytes()
+}
+
+// errorComments lists the errors of the last save as comments for the top of the re-opened file
+func errorComments(err error) string {
+	var out bytes.Buffer
+	out.WriteString("# The edited file had errors:\n")
+	if errs, ok := err.(model.ValidationError); ok {
+		for _, fe := range errs {
+			fmt.Fprintf(&out, "# * %s\n", fe.Error())
+		}
+	} else {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(&out, "# * %s\n", line)
+		}
+	}
+	out.WriteString("#\n")
+	return out.String()
+}
+
+// keepEdit saves the edited content when it cannot be stored so that the changes are not lost
+func keepEdit(edited []byte) string {
+	file, err := ioutil.TempFile("", "templator-edit-kept-")
+	if err != nil {
+		return "nowhere, they could not be written"
+	}
+	defer file.Close()
+	file.Write(edited)
+	return file.Name()
+}
+
+func editDescription(templateName, kind, name string) string {
+	if "" == kind {
+		return "template " + templateName
+	}
+	return fmt.Sprintf("%s %s in template %s", kind, name, templateName)
+}
@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/export"
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+func GraphCmd() cli.Command {
+	return cli.Command{
+		Name:         "graph",
+		ArgsUsage:    "<template>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "graph <template> --format=dot|mermaid --nodes=3 draws the generated objects and what they reference",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "format",
+				Value: "dot",
+				Usage: "--format=dot|mermaid",
+			},
+			cli.IntFlag{
+				Name:  "nodes",
+				Value: 1,
+				Usage: "--nodes=3 the node count to generate for",
+			},
+			cli.StringFlag{
+				Name:  "profile",
+				Usage: "--profile=prod generates with a stored profile, --nodes overrides its node count",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 1 {
+				return cli.NewExitError("expected one arg "+context.Command.ArgsUsage, 1)
+			}
+			if err := GraphAction(context.Args()[0], context.String("profile"), context.Int("nodes"), context.IsSet("nodes"), context.String("format")); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// GraphAction prints the graph of the template. The node count only overrides the profile's when setNodes is true
+func GraphAction(templateName, profile string, nodes int, setNodes bool, format string) error {
+	appTemp, err := service.NewTemplateService("local").GetTemplate(templateName)
+	if err != nil {
+		return err
+	}
+	if nil == appTemp {
+		return fmt.Errorf("no template named %s", templateName)
+	}
+	opts := generate.Options{Nodes: nodes}
+	if "" != profile {
+		if opts, err = generate.ProfileOptions(appTemp, profile); err != nil {
+			return err
+		}
+		if setNodes {
+			opts.Nodes = nodes
+		}
+	}
+	data, warnings, err := export.Graph(appTemp, opts, format)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "warning: "+w)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,123 @@
+package label
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+func LabelCmd() cli.Command {
+	return cli.Command{
+		Name:         "label",
+		ArgsUsage:    "<template> <key=value|key-> ...",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "label <template> app=myapp team=payments version- sets or removes (key-) labels added to every generated object",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "selector",
+				Usage: "--selector also adds the labels set to the selectors matching the pods, only for labels that never change as a changed selector orphans running pods",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			selector := context.Bool("selector")
+			return changeAction(context, func(appTemp *model.ApplicationTemplate) *map[string]string {
+				return &appTemp.ObjectLabels
+			}, func(appTemp *model.ApplicationTemplate, values map[string]string, remove []string) {
+				if selector {
+					for k := range values {
+						appTemp.SelectorLabels = appendMissing(appTemp.SelectorLabels, k)
+					}
+					sort.Strings(appTemp.SelectorLabels)
+				}
+				for _, k := range remove {
+					appTemp.SelectorLabels = removeString(appTemp.SelectorLabels, k)
+				}
+			})
+		},
+	}
+}
+
+func AnnotateCmd() cli.Command {
+	return cli.Command{
+		Name:         "annotate",
+		ArgsUsage:    "<template> <key=value|key-> ...",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "annotate <template> owner=me owner- sets or removes (key-) annotations added to every generated object",
+		Action: func(context *cli.Context) error {
+			return changeAction(context, func(appTemp *model.ApplicationTemplate) *map[string]string {
+				return &appTemp.ObjectAnnotations
+			}, nil)
+		},
+	}
+}
+
+func changeAction(context *cli.Context, field func(appTemp *model.ApplicationTemplate) *map[string]string, changed func(appTemp *model.ApplicationTemplate, values map[string]string, remove []string)) error {
+	if len(context.Args()) < 2 {
+		return cli.NewExitError("expected at least two args "+context.Command.ArgsUsage, 1)
+	}
+	var (
+		set    []string
+		remove []string
+	)
+	for _, arg := range context.Args()[1:] {
+		if strings.HasSuffix(arg, "-") && !strings.Contains(arg, "=") {
+			remove = append(remove, strings.TrimSuffix(arg, "-"))
+			continue
+		}
+		set = append(set, arg)
+	}
+	values, err := cmd.ParseKeyValues(set)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if err := ChangeAction(context.Args()[0], values, remove, field, changed); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return nil
+}
+
+// ChangeAction sets and removes keys in the label or annotation map chosen by field. changed, when given, is called
+// with the same keys so that whatever else depends on them is kept in step
+func ChangeAction(temp string, values map[string]string, remove []string, field func(appTemp *model.ApplicationTemplate) *map[string]string, changed func(appTemp *model.ApplicationTemplate, values map[string]string, remove []string)) error {
+	return service.NewTemplateService("local").UpdateTemplate(temp, func(appTemp *model.ApplicationTemplate) error {
+		target := field(appTemp)
+		if nil == *target {
+			*target = make(map[string]string)
+		}
+		for k, v := range values {
+			(*target)[k] = v
+		}
+		for _, k := range remove {
+			delete(*target, k)
+		}
+		if len(*target) == 0 {
+			*target = nil
+		}
+		if changed != nil {
+			changed(appTemp, values, remove)
+		}
+		return nil
+	})
+}
+
+func appendMissing(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func removeString(list []string, s string) []string {
+	for i, l := range list {
+		if l == s {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
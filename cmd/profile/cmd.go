@@ -0,0 +1,153 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+func ProfileCmd() cli.Command {
+	return cli.Command{
+		Name:  "profile",
+		Usage: "manage the named generation profiles of a template",
+		Subcommands: []cli.Command{
+			SetProfileCmd(),
+			ListProfileCmd(),
+			DeleteProfileCmd(),
+		},
+	}
+}
+
+func SetProfileCmd() cli.Command {
+	return cli.Command{
+		Name:         "set",
+		ArgsUsage:    "<template> <name> <key=value> ...",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames("profile")),
+		Usage:        "set <template> prod nodes=5 storage=true nodeSelector=true namespace=app-prod",
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) < 3 {
+				return cli.NewExitError("expected at least three args "+context.Command.ArgsUsage, 1)
+			}
+			values, err := cmd.ParseKeyValues(context.Args()[2:])
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			if err := SetProfileAction(context.Args()[0], context.Args()[1], values); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func ListProfileCmd() cli.Command {
+	return cli.Command{
+		Name:         "list",
+		ArgsUsage:    "<template>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "list <template>",
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 1 {
+				return cli.NewExitError("expected one arg "+context.Command.ArgsUsage, 1)
+			}
+			if err := ListProfileAction(context.Args()[0]); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func DeleteProfileCmd() cli.Command {
+	return cli.Command{
+		Name:         "delete",
+		ArgsUsage:    "<template> <name>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames("profile")),
+		Usage:        "delete <template> <name>",
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := DeleteProfileAction(context.Args()[0], context.Args()[1]); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// SetProfileAction creates the profile or changes the given keys of an existing one
+func SetProfileAction(temp, name string, values map[string]string) error {
+	return service.NewTemplateService("local").UpdateTemplate(temp, func(appTemp *model.ApplicationTemplate) error {
+		if nil == appTemp.Profiles {
+			appTemp.Profiles = make(map[string]*model.Profile)
+		}
+		profile, ok := appTemp.Profiles[name]
+		if !ok {
+			profile = &model.Profile{}
+		}
+		for k, v := range values {
+			var err error
+			switch k {
+			case "nodes":
+				profile.Nodes, err = strconv.Atoi(v)
+			case "storage":
+				profile.Storage, err = strconv.ParseBool(v)
+			case "nodeSelector":
+				profile.NodeSelector, err = strconv.ParseBool(v)
+			case "namespace":
+				profile.Namespace = v
+			default:
+				return fmt.Errorf("unknown profile key %s expected nodes, storage, nodeSelector or namespace", k)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid value %s for %s %s", v, k, err.Error())
+			}
+		}
+		if profile.Nodes < 0 {
+			return fmt.Errorf("nodes cannot be negative")
+		}
+		appTemp.Profiles[name] = profile
+		return nil
+	})
+}
+
+func ListProfileAction(temp string) error {
+	appTemp, err := service.NewTemplateService("local").GetTemplate(temp)
+	if err != nil {
+		return err
+	}
+	if nil == appTemp {
+		return fmt.Errorf("no template named %s", temp)
+	}
+	names := make([]string, 0, len(appTemp.Profiles))
+	for name := range appTemp.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join([]string{"NAME", "NODES", "STORAGE", "NODESELECTOR", "NAMESPACE"}, "\t"))
+	for _, name := range names {
+		p := appTemp.Profiles[name]
+		fmt.Fprintf(w, "%s\t%d\t%t\t%t\t%s\n", name, p.Nodes, p.Storage, p.NodeSelector, p.Namespace)
+	}
+	return w.Flush()
+}
+
+func DeleteProfileAction(temp, name string) error {
+	return service.NewTemplateService("local").UpdateTemplate(temp, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.Profiles[name]; !ok {
+			return fmt.Errorf("no profile named %s in template %s", name, temp)
+		}
+		delete(appTemp.Profiles, name)
+		return nil
+	})
+}
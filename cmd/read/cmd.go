@@ -7,6 +7,9 @@ func ReadCmd() cli.Command {
 		Name: "read",
 		Subcommands: []cli.Command{
 			ReadTemplateCmd(),
+			ReadDeploymentCmd(),
+			ReadServiceCmd(),
+			ReadRouteCmd(),
 		},
 	}
 }
@@ -0,0 +1,153 @@
+package read
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// executeJSONPath supports the subset of kubectl jsonpath needed to pick fields out of stored objects:
+// literal text mixed with {.field.sub[0]['key'][*]} expressions
+func executeJSONPath(w io.Writer, expr string, data interface{}) error {
+	for len(expr) > 0 {
+		start := strings.Index(expr, "{")
+		if start == -1 {
+			_, err := io.WriteString(w, expr)
+			return err
+		}
+		if _, err := io.WriteString(w, expr[:start]); err != nil {
+			return err
+		}
+		end := strings.Index(expr[start:], "}")
+		if end == -1 {
+			return fmt.Errorf("unclosed { in jsonpath %s", expr)
+		}
+		results, err := evalJSONPath(expr[start+1:start+end], data)
+		if err != nil {
+			return err
+		}
+		values := make([]string, 0, len(results))
+		for _, r := range results {
+			v, err := jsonPathValue(r)
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+		}
+		if _, err := io.WriteString(w, strings.Join(values, " ")); err != nil {
+			return err
+		}
+		expr = expr[start+end+1:]
+	}
+	return nil
+}
+
+func evalJSONPath(path string, data interface{}) ([]interface{}, error) {
+	path = strings.TrimSpace(path)
+	current := []interface{}{data}
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			if end == 0 {
+				continue
+			}
+			current = selectField(current, path[:end])
+			path = path[end:]
+		case '[':
+			end := strings.Index(path, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed [ in jsonpath %s", path)
+			}
+			var err error
+			if current, err = selectIndex(current, path[1:end]); err != nil {
+				return nil, err
+			}
+			path = path[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %s in jsonpath expected . or [", path)
+		}
+	}
+	return current, nil
+}
+
+func selectField(current []interface{}, field string) []interface{} {
+	var next []interface{}
+	for _, c := range current {
+		switch v := c.(type) {
+		case map[string]interface{}:
+			if "*" == field {
+				next = append(next, sortedValues(v)...)
+			} else if f, ok := v[field]; ok {
+				next = append(next, f)
+			}
+		case []interface{}:
+			if "*" == field {
+				next = append(next, v...)
+			}
+		}
+	}
+	return next
+}
+
+func selectIndex(current []interface{}, index string) ([]interface{}, error) {
+	index = strings.TrimSpace(index)
+	if strings.HasPrefix(index, "'") || strings.HasPrefix(index, "\"") {
+		return selectField(current, strings.Trim(index, "'\"")), nil
+	}
+	if "*" == index {
+		return selectField(current, "*"), nil
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported index [%s] in jsonpath", index)
+	}
+	var next []interface{}
+	for _, c := range current {
+		list, ok := c.([]interface{})
+		if !ok {
+			continue
+		}
+		pos := i
+		if pos < 0 {
+			pos = len(list) + pos
+		}
+		if pos >= 0 && pos < len(list) {
+			next = append(next, list[pos])
+		}
+	}
+	return next, nil
+}
+
+func sortedValues(m map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]interface{}, 0, len(m))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+	return values
+}
+
+func jsonPathValue(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case nil:
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,86 @@
+package read
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/urfave/cli"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+func ReadDeploymentCmd() cli.Command {
+	return readObjectCmd("deployment", ReadDeploymentAction)
+}
+
+func ReadServiceCmd() cli.Command {
+	return readObjectCmd("service", ReadServiceAction)
+}
+
+func ReadRouteCmd() cli.Command {
+	return readObjectCmd("route", ReadRouteAction)
+}
+
+// readObjectCmd builds a "<kind> <template> <name>" command that prints a single object from a stored template
+func readObjectCmd(kind string, readAction func(temp, name, output string) error) cli.Command {
+	return cli.Command{
+		Name:         kind,
+		ArgsUsage:    "<template> <name>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, cmd.ObjectNames(kind)),
+		Usage:        "<template> <name> -o json|yaml|go-template=...|jsonpath=...",
+		Flags:        []cli.Flag{outputFlag()},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 2 {
+				return cli.NewExitError("expected two args "+context.Command.ArgsUsage, 1)
+			}
+			if err := readAction(context.Args()[0], context.Args()[1], flag_Output); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func ReadDeploymentAction(temp, name, output string) error {
+	appTemp, err := getTemplate(temp)
+	if err != nil {
+		return err
+	}
+	dc, ok := appTemp.DeploymentConfigs[name]
+	if !ok {
+		return fmt.Errorf("no deployment named %s in template %s", name, temp)
+	}
+	return printObject(os.Stdout, dc, output, func(w io.Writer) error {
+		return executeTable(w, "deployment", DEPLOYMENTS_TEMPLATE, map[string]*model.OSTDeploymentConfig{name: dc})
+	})
+}
+
+func ReadServiceAction(temp, name, output string) error {
+	appTemp, err := getTemplate(temp)
+	if err != nil {
+		return err
+	}
+	s, ok := appTemp.Services[name]
+	if !ok {
+		return fmt.Errorf("no service named %s in template %s", name, temp)
+	}
+	return printObject(os.Stdout, s, output, func(w io.Writer) error {
+		return executeTable(w, "service", SERVICES_TEMPLATE, map[string]*k8.Service{name: s})
+	})
+}
+
+func ReadRouteAction(temp, name, output string) error {
+	appTemp, err := getTemplate(temp)
+	if err != nil {
+		return err
+	}
+	r, ok := appTemp.Routes[name]
+	if !ok {
+		return fmt.Errorf("no route named %s in template %s", name, temp)
+	}
+	return printObject(os.Stdout, r, output, func(w io.Writer) error {
+		return executeTable(w, "route", ROUTES_TEMPLATE, map[string]*model.Route{name: r})
+	})
+}
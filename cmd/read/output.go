@@ -0,0 +1,205 @@
+package read
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/maleck13/templator/model"
+	"github.com/urfave/cli"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+var (
+	flag_Output string
+)
+
+const (
+	TEMPLATE_SUMMARY_TEMPLATE = `Name:	{{.Name}}
+Description:	{{index .Annotations "description"}}
+`
+	DEPLOYMENTS_TEMPLATE = `NAME	REPLICAS	CONTAINERS	IMAGES	PORTS	STRATEGY
+{{range $k,$v := .}}{{$k}}	{{$v.Spec.Replicas}}	{{containers $v}}	{{images $v}}	{{containerPorts $v}}	{{$v.Spec.Strategy.Type}}
+{{end}}`
+	SERVICES_TEMPLATE = `NAME	TYPE	PORTS	SELECTOR
+{{range $k,$v := .}}{{$v.Name}}	{{$v.Spec.Type}}	{{servicePorts $v}}	{{selector $v.Spec.Selector}}
+{{end}}`
+	ROUTES_TEMPLATE = `NAME	HOST	PATH	SERVICE	TLS
+{{range $k,$v := .}}{{$v.Name}}	{{$v.Spec.Host}}	{{$v.Spec.Path}}	{{$v.Spec.To.Name}}	{{if $v.Spec.TLS}}{{$v.Spec.TLS.Termination}}{{end}}
+{{end}}`
+	VOLUMES_TEMPLATE = `NAME	ACCESS MODES	STORAGE
+{{range $k,$v := .}}{{$k}}	{{accessModes $v}}	{{storage $v}}
+{{end}}`
+	PARAMETERS_TEMPLATE = `NAME	VALUE	GENERATE	REQUIRED
+{{range .}}{{.Name}}	{{.Value}}	{{.Generate}}	{{.Required}}
+{{end}}`
+)
+
+func outputFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:        "output, o",
+		Usage:       "-o json|yaml|go-template=...|jsonpath=... defaults to a summary table",
+		Destination: &flag_Output,
+	}
+}
+
+var tableFuncs = template.FuncMap{
+	"containers": func(dc *model.OSTDeploymentConfig) string {
+		var names []string
+		for _, c := range podContainers(dc) {
+			names = append(names, c.Name)
+		}
+		return strings.Join(names, ",")
+	},
+	"images": func(dc *model.OSTDeploymentConfig) string {
+		var images []string
+		for _, c := range podContainers(dc) {
+			images = append(images, c.Image)
+		}
+		return strings.Join(images, ",")
+	},
+	"containerPorts": func(dc *model.OSTDeploymentConfig) string {
+		var ports []string
+		for _, c := range podContainers(dc) {
+			for _, p := range c.Ports {
+				ports = append(ports, fmt.Sprintf("%d/%s", p.ContainerPort, p.Protocol))
+			}
+		}
+		return strings.Join(ports, ",")
+	},
+	"servicePorts": func(s *k8.Service) string {
+		var ports []string
+		for _, p := range s.Spec.Ports {
+			ports = append(ports, fmt.Sprintf("%d->%s/%s", p.Port, p.TargetPort.String(), p.Protocol))
+		}
+		return strings.Join(ports, ",")
+	},
+	"selector": func(selector map[string]string) string {
+		var pairs []string
+		for k, v := range selector {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	},
+	"accessModes": func(pvc *k8.PersistentVolumeClaim) string {
+		var modes []string
+		for _, m := range pvc.Spec.AccessModes {
+			modes = append(modes, string(m))
+		}
+		return strings.Join(modes, ",")
+	},
+	"storage": func(pvc *k8.PersistentVolumeClaim) string {
+		if q, ok := pvc.Spec.Resources.Requests[k8.ResourceStorage]; ok {
+			return q.String()
+		}
+		return ""
+	},
+}
+
+func podContainers(dc *model.OSTDeploymentConfig) []k8.Container {
+	if dc.Spec.Template == nil {
+		return nil
+	}
+	return dc.Spec.Template.Spec.Containers
+}
+
+// printObject writes obj in the format requested by the output flag, falling back to the summary when no format is given
+func printObject(out io.Writer, obj interface{}, output string, summary func(io.Writer) error) error {
+	switch {
+	case "" == output:
+		w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		if err := summary(w); err != nil {
+			return err
+		}
+		return w.Flush()
+	case "json" == output:
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml" == output:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	case strings.HasPrefix(output, "go-template="):
+		generic, err := toGeneric(obj)
+		if err != nil {
+			return err
+		}
+		t, err := template.New("output").Parse(strings.TrimPrefix(output, "go-template="))
+		if err != nil {
+			return fmt.Errorf("failed to parse go-template %s", err.Error())
+		}
+		return t.Execute(out, generic)
+	case strings.HasPrefix(output, "jsonpath="):
+		generic, err := toGeneric(obj)
+		if err != nil {
+			return err
+		}
+		return executeJSONPath(out, strings.TrimPrefix(output, "jsonpath="), generic)
+	}
+	return fmt.Errorf("unsupported output format %s expected json|yaml|go-template=...|jsonpath=...", output)
+}
+
+// toGeneric converts obj to the maps and slices of its json form so that templates and paths use the json field names
+func toGeneric(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func executeTable(w io.Writer, name, table string, data interface{}) error {
+	t, err := template.New(name).Funcs(tableFuncs).Parse(table)
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s", err.Error())
+	}
+	return t.Execute(w, data)
+}
+
+func printTemplateSummary(w io.Writer, appTemp *model.ApplicationTemplate) error {
+	if err := executeTable(w, "summary", TEMPLATE_SUMMARY_TEMPLATE, appTemp); err != nil {
+		return err
+	}
+	sections := []struct {
+		title string
+		table string
+		data  interface{}
+		size  int
+	}{
+		{"DEPLOYMENTS", DEPLOYMENTS_TEMPLATE, appTemp.DeploymentConfigs, len(appTemp.DeploymentConfigs)},
+		{"SERVICES", SERVICES_TEMPLATE, appTemp.Services, len(appTemp.Services)},
+		{"ROUTES", ROUTES_TEMPLATE, appTemp.Routes, len(appTemp.Routes)},
+		{"VOLUMES", VOLUMES_TEMPLATE, appTemp.PersistentVolumes, len(appTemp.PersistentVolumes)},
+		{"PARAMETERS", PARAMETERS_TEMPLATE, appTemp.Parameters, len(appTemp.Parameters)},
+	}
+	for _, s := range sections {
+		if s.size == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", s.title)
+		if err := executeTable(w, s.title, s.table, s.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
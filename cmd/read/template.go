@@ -1,30 +1,33 @@
 package read
 
 import (
-	"encoding/json"
+	"fmt"
+	"io"
 	"os"
-	"text/template"
 
-	"github.com/urfave/cli"
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/secret"
 	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
 )
 
-const LIST_TEMPLATES_TEMPLATE = `
-{{range $k,$v := .}}
- | {{$k}} |
-{{end}}
-`
+const LIST_TEMPLATES_TEMPLATE = `NAME	DEPLOYMENTS	SERVICES	ROUTES	VOLUMES	PARAMETERS
+{{range $k,$v := .}}{{$k}}	{{len $v.DeploymentConfigs}}	{{len $v.Services}}	{{len $v.Routes}}	{{len $v.PersistentVolumes}}	{{len $v.Parameters}}
+{{end}}`
 
 func ReadTemplateCmd() cli.Command {
 	return cli.Command{
-		Name:      "app_template",
-		ArgsUsage: "[name]",
-		Usage:     "[name]",
+		Name:         "app_template",
+		ArgsUsage:    "[name]",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "[name] -o json|yaml|go-template=...|jsonpath=...",
+		Flags:        []cli.Flag{outputFlag()},
 		Action: func(context *cli.Context) error {
 			if len(context.Args()) != 1 {
-				return ListTemplateAction()
+				return ListTemplateAction(flag_Output)
 			}
-			if err := ReadTemplateAction(context.Args()[0]); err != nil {
+			if err := ReadTemplateAction(context.Args()[0], flag_Output); err != nil {
 				return cli.NewExitError(err.Error(), 1)
 			}
 			return nil
@@ -32,33 +35,47 @@ func ReadTemplateCmd() cli.Command {
 	}
 }
 
-func ListTemplateAction() error {
+func ListTemplateAction(output string) error {
 	templateService := service.NewTemplateService("local")
 	data, err := templateService.ListTemplates()
 	if err != nil {
 		return cli.NewExitError("failed to load templates "+err.Error(), 1)
 	}
-	t := template.New("templatesList")
-	outT, err := t.Parse(LIST_TEMPLATES_TEMPLATE)
-	if err != nil {
-		return cli.NewExitError("failed to parse template "+err.Error(), 1)
+	for _, appTemp := range data {
+		secret.MaskTemplate(appTemp)
 	}
-	if err := outT.Execute(os.Stdout, data); err != nil {
-		return cli.NewExitError("failed to execute template "+err.Error(), 1)
+	if err := printObject(os.Stdout, data, output, func(w io.Writer) error {
+		return executeTable(w, "templatesList", LIST_TEMPLATES_TEMPLATE, data)
+	}); err != nil {
+		return cli.NewExitError(err.Error(), 1)
 	}
 	return nil
 }
 
-func ReadTemplateAction(name string) error {
+func ReadTemplateAction(name, output string) error {
+	appTemp, err := getTemplate(name)
+	if err != nil {
+		return err
+	}
+	return PrintTemplate(os.Stdout, appTemp, output)
+}
+
+// PrintTemplate writes appTemp in the output format, or as a summary of its objects when output is empty
+func PrintTemplate(out io.Writer, appTemp *model.ApplicationTemplate, output string) error {
+	return printObject(out, appTemp, output, func(w io.Writer) error {
+		return printTemplateSummary(w, appTemp)
+	})
+}
+
+func getTemplate(name string) (*model.ApplicationTemplate, error) {
 	templateService := service.NewTemplateService("local")
 	appTemp, err := templateService.GetTemplate(name)
 	if err != nil {
-		return cli.NewExitError(err.Error(), 1)
+		return nil, err
 	}
-	decoder := json.NewDecoder(os.Stdout)
-
-	if err := decoder.Decode(appTemp); err != nil {
-		return cli.NewExitError(err.Error(), 1)
+	if nil == appTemp {
+		return nil, fmt.Errorf("no template named %s", name)
 	}
-	return nil
+	secret.MaskTemplate(appTemp)
+	return appTemp, nil
 }
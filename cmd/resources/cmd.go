@@ -0,0 +1,152 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/maleck13/templator/capacity"
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+func ResourcesCmd() cli.Command {
+	return cli.Command{
+		Name:         "resources",
+		ArgsUsage:    "<template>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "resources <template> --nodes=5 --inventory=nodes.yaml totals the resources the generated pods and claims ask for",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "nodes",
+				Usage: "--nodes=5 the node count to generate for, defaults to the number of inventory nodes or 1",
+			},
+			cli.StringFlag{
+				Name:  "profile",
+				Usage: "--profile=prod generates with a stored profile, --nodes overrides its node count",
+			},
+			cli.BoolFlag{
+				Name:  "nodeSelector",
+				Usage: "--nodeSelector keeps node selectors so the fit only uses matching inventory nodes",
+			},
+			cli.StringFlag{
+				Name:  "inventory",
+				Usage: "--inventory=<file> a json or yaml list of nodes {name, labels, capacity: {cpu, memory, pods}} to fit the pods to",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 1 {
+				return cli.NewExitError("expected one arg "+context.Command.ArgsUsage, 1)
+			}
+			templateName := context.Args()[0]
+			appTemp, err := service.NewTemplateService("local").GetTemplate(templateName)
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			if nil == appTemp {
+				return cli.NewExitError("no template named "+templateName, 1)
+			}
+			var inventory []capacity.Node
+			if file := context.String("inventory"); "" != file {
+				if inventory, err = capacity.LoadInventory(file); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+			}
+			opts := generate.Options{Nodes: 1}
+			if len(inventory) > 0 {
+				opts.Nodes = len(inventory)
+			}
+			if profile := context.String("profile"); "" != profile {
+				if opts, err = generate.ProfileOptions(appTemp, profile); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+			}
+			if context.IsSet("nodes") {
+				opts.Nodes = context.Int("nodes")
+			}
+			if context.IsSet("nodeSelector") {
+				opts.NodeSelector = context.Bool("nodeSelector")
+			}
+			//the storage the template claims is always counted
+			opts.Storage = true
+			fits, err := ResourcesAction(appTemp, opts, inventory)
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			if !fits {
+				return cli.NewExitError("the pods do not fit the inventory", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// ResourcesAction prints the resources of the template generated with opts and, when an inventory is given, where
+// the pods would be placed. It returns false when some pods do not fit the inventory
+func ResourcesAction(appTemp *model.ApplicationTemplate, opts generate.Options, inventory []capacity.Node) (bool, error) {
+	result, err := generate.Generate(appTemp, opts)
+	if err != nil {
+		return false, err
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, "warning: "+w)
+	}
+	report := capacity.Calculate(result.Objects)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "generated for %d nodes\n\n", opts.Nodes)
+	fmt.Fprintln(w, "DEPLOYMENT\tREPLICAS\tCPU REQUESTS\tCPU LIMITS\tMEMORY REQUESTS\tMEMORY LIMITS")
+	for _, wl := range report.Workloads {
+		replicas := strconv.Itoa(wl.Replicas)
+		if wl.Autoscaled {
+			replicas = "up to " + replicas
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", wl.Name, replicas, quantity(wl.Requests, k8.ResourceCPU), quantity(wl.Limits, k8.ResourceCPU),
+			quantity(wl.Requests, k8.ResourceMemory), quantity(wl.Limits, k8.ResourceMemory))
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%s\t%s\t%s\t%s\n", quantity(report.Requests, k8.ResourceCPU), quantity(report.Limits, k8.ResourceCPU),
+		quantity(report.Requests, k8.ResourceMemory), quantity(report.Limits, k8.ResourceMemory))
+	if len(report.Storage) > 0 {
+		fmt.Fprintln(w, "\nSTORAGE CLASS\tCLAIMS\tSTORAGE")
+		for _, s := range report.Storage {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", s.Class, s.Claims, s.Size.String())
+		}
+	}
+	if len(inventory) == 0 {
+		return true, w.Flush()
+	}
+
+	fit := capacity.Fit(report.Workloads, inventory)
+	fmt.Fprintln(w, "\nNODE\tPODS\tCPU REQUESTS\tCPU CAPACITY\tMEMORY REQUESTS\tMEMORY CAPACITY")
+	pods := make(map[string][]string)
+	for _, p := range fit.Placements {
+		pods[p.Node] = append(pods[p.Node], p.Pod)
+	}
+	for _, n := range inventory {
+		sort.Strings(pods[n.Name])
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", n.Name, len(pods[n.Name]), quantity(fit.Allocated[n.Name], k8.ResourceCPU), quantity(n.Capacity, k8.ResourceCPU),
+			quantity(fit.Allocated[n.Name], k8.ResourceMemory), quantity(n.Capacity, k8.ResourceMemory))
+	}
+	if fit.Unscheduled > 0 {
+		fmt.Fprintln(w, "\nUNSCHEDULED POD\tREASON")
+		for _, p := range fit.Placements {
+			if "" == p.Node {
+				fmt.Fprintf(w, "%s\t%s\n", p.Pod, p.Reason)
+			}
+		}
+	}
+	return fit.Unscheduled == 0, w.Flush()
+}
+
+func quantity(list k8.ResourceList, name k8.ResourceName) string {
+	if q, ok := list[name]; ok {
+		return q.String()
+	}
+	return "-"
+}
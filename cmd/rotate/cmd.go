@@ -0,0 +1,76 @@
+package rotate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/secret"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+func RotateKeyCmd() cli.Command {
+	return cli.Command{
+		Name:  "rotate-key",
+		Usage: "rotate-key re-encrypts the sensitive values of the store with a new key, the previous key is kept as <key-file>.old",
+		Action: func(context *cli.Context) error {
+			if err := RotateKeyAction(secret.KeyLocation); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// RotateKeyAction re-encrypts the store with a new key. The new key is written next to the old one before the store is
+// changed so that it is never lost, and only replaces the old key once the store is saved
+func RotateKeyAction(keyLocation string) error {
+	templateService := service.NewTemplateService("local")
+	templates, err := templateService.ListTemplates()
+	if err != nil {
+		return err
+	}
+	var oldKey []byte
+	for _, appTemp := range templates {
+		if secret.HasEncrypted(appTemp) {
+			if oldKey, err = secret.LoadKey(keyLocation, false); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	newKey, err := secret.NewKey()
+	if err != nil {
+		return err
+	}
+	if err := secret.WriteKey(keyLocation+".new", newKey); err != nil {
+		return err
+	}
+	rotated := 0
+	err = templateService.UpdateTemplates(func(appTemp *model.ApplicationTemplate) (bool, error) {
+		if !secret.HasEncrypted(appTemp) {
+			return false, nil
+		}
+		if nil == oldKey {
+			//encrypted values were stored after the templates were first listed
+			return false, fmt.Errorf("the store changed while rotating, run rotate-key again")
+		}
+		rotated++
+		return true, secret.ReencryptTemplate(appTemp, oldKey, newKey)
+	})
+	if err != nil {
+		os.Remove(keyLocation + ".new")
+		return err
+	}
+	if _, err := os.Stat(keyLocation); err == nil {
+		if err := os.Rename(keyLocation, keyLocation+".old"); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(keyLocation+".new", keyLocation); err != nil {
+		return fmt.Errorf("the store is encrypted with the key at %s.new but it could not be moved to %s %s", keyLocation, keyLocation, err.Error())
+	}
+	fmt.Printf("rotated the key at %s, re-encrypted %d templates\n", keyLocation, rotated)
+	return nil
+}
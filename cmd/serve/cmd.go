@@ -0,0 +1,61 @@
+package serve
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/maleck13/templator/server"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+const DEFAULT_ADDR = "127.0.0.1:8080"
+
+func ServeCmd() cli.Command {
+	return cli.Command{
+		Name:  "serve",
+		Usage: "serve --addr=127.0.0.1:8080 serves the template store as a REST/JSON API under /templates",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "addr",
+				Value: DEFAULT_ADDR,
+				Usage: "--addr=<host:port> the address to listen on",
+			},
+			cli.BoolFlag{
+				Name:  "allow-remote",
+				Usage: "--allow-remote listens on an --addr other hosts can reach, the API has no authentication and generate returns decrypted values",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if err := ServeAction(context.String("addr"), context.Bool("allow-remote")); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// ServeAction serves the API for the store until the listener fails. As anyone who can connect can read and change
+// the store, addresses other hosts can reach are refused unless allowRemote is set
+func ServeAction(addr string, allowRemote bool) error {
+	if !allowRemote && !isLoopback(addr) {
+		return fmt.Errorf("%s can be reached from other hosts and the API has no authentication, listen on 127.0.0.1 or pass --allow-remote", addr)
+	}
+	handler := server.NewHandler(service.NewTemplateService("local"))
+	fmt.Printf("serving %s on http://%s/templates\n", service.StoreLocation, addr)
+	return http.ListenAndServe(addr, handler)
+}
+
+// isLoopback reports whether addr only listens on the loopback interface. An empty host listens on every interface
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if "localhost" == host {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,297 @@
+package set
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/patch"
+	"github.com/maleck13/templator/secret"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+func SetCmd() cli.Command {
+	return cli.Command{
+		Name:         "set",
+		ArgsUsage:    "<template> <kind>/<name> [<path>=<value>|<path>-] ...",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames, setObjects),
+		Usage: "set <template> deployment/web spec.template.spec.containers[name=web].image=nginx:1.13 spec.replicas=3 " +
+			"or set <template> service/web --patch='{\"spec\":{\"type\":\"NodePort\"}}'",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "patch",
+				Usage: "--patch=<json|yaml|@file> a json merge patch object or a json patch list of operations applied before any paths",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			args := context.Args()
+			if len(args) < 2 || (len(args) == 2 && "" == context.String("patch")) {
+				return cli.NewExitError("expected a template, an object and paths or --patch "+context.Command.ArgsUsage, 1)
+			}
+			var patchDoc []byte
+			if p := context.String("patch"); "" != p {
+				var err error
+				if patchDoc, err = readPatch(p); err != nil {
+					return cli.NewExitError(err.Error(), 1)
+				}
+			}
+			if err := SetAction(args[0], args[1], patchDoc, args[2:]); err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
+
+func setObjects(args []string) []string {
+	var candidates []string
+	for _, kind := range []string{"deployment", "service", "route"} {
+		for _, name := range cmd.ObjectNames(kind)(args) {
+			candidates = append(candidates, kind+"/"+name)
+		}
+	}
+	return candidates
+}
+
+func readPatch(p string) ([]byte, error) {
+	data := []byte(p)
+	if strings.HasPrefix(p, "@") {
+		var err error
+		if data, err = ioutil.ReadFile(p[1:]); err != nil {
+			return nil, err
+		}
+	}
+	//yaml is a superset of json so either is accepted
+	return yaml.YAMLToJSON(data)
+}
+
+// storedObject gets, decodes, validates and puts back one kind of stored object
+type storedObject struct {
+	get      func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool)
+	decode   func(data []byte) (interface{}, error)
+	validate func(obj interface{}) error
+	put      func(appTemp *model.ApplicationTemplate, name string, obj interface{})
+}
+
+var kinds = map[string]storedObject{
+	"deployment": {
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			dc, ok := appTemp.DeploymentConfigs[name]
+			return dc, ok
+		},
+		decode: func(data []byte) (interface{}, error) {
+			dc := &model.OSTDeploymentConfig{}
+			return dc, json.Unmarshal(data, dc)
+		},
+		validate: func(obj interface{}) error {
+			return model.ValidateDeploymentConfig(obj.(*model.OSTDeploymentConfig))
+		},
+		put: func(appTemp *model.ApplicationTemplate, name string, obj interface{}) {
+			appTemp.DeploymentConfigs[name] = obj.(*model.OSTDeploymentConfig)
+		},
+	},
+	"service": {
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			s, ok := appTemp.Services[name]
+			return s, ok
+		},
+		decode: func(data []byte) (interface{}, error) {
+			s := &k8.Service{}
+			return s, json.Unmarshal(data, s)
+		},
+		validate: func(obj interface{}) error {
+			return model.ValidateService(obj.(*k8.Service))
+		},
+		put: func(appTemp *model.ApplicationTemplate, name string, obj interface{}) {
+			appTemp.Services[name] = obj.(*k8.Service)
+		},
+	},
+	"route": {
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			r, ok := appTemp.Routes[name]
+			return r, ok
+		},
+		decode: func(data []byte) (interface{}, error) {
+			r := &model.Route{}
+			return r, json.Unmarshal(data, r)
+		},
+		validate: func(obj interface{}) error {
+			return model.ValidateRoute(obj.(*model.Route))
+		},
+		put: func(appTemp *model.ApplicationTemplate, name string, obj interface{}) {
+			appTemp.Routes[name] = obj.(*model.Route)
+		},
+	},
+}
+
+var kindAliases = map[string]string{
+	"dc":               "deployment",
+	"deploymentconfig": "deployment",
+	"svc":              "service",
+}
+
+// SetAction applies patchDoc and then each path assignment to the object, given as kind/name, and saves it if the
+// result is valid. Nothing is saved if any change fails. Values that were encrypted are encrypted again when set
+func SetAction(templateName, object string, patchDoc []byte, assignments []string) error {
+	kindName := strings.SplitN(object, "/", 2)
+	if len(kindName) != 2 || "" == kindName[1] {
+		return fmt.Errorf("expected <kind>/<name> but got %s", object)
+	}
+	kind, name := strings.ToLower(kindName[0]), kindName[1]
+	if alias, ok := kindAliases[kind]; ok {
+		kind = alias
+	}
+	stored, ok := kinds[kind]
+	if !ok {
+		return fmt.Errorf("cannot set fields of %s expected deployment, service or route", kind)
+	}
+	return service.NewTemplateService("local").UpdateTemplate(templateName, func(appTemp *model.ApplicationTemplate) error {
+		obj, ok := stored.get(appTemp, name)
+		if !ok {
+			return fmt.Errorf("no %s named %s in template %s", kind, name, templateName)
+		}
+		previous, err := appTemp.Copy()
+		if err != nil {
+			return err
+		}
+		doc, err := toGeneric(obj)
+		if err != nil {
+			return err
+		}
+		objectName := metadataName(doc)
+		if len(patchDoc) > 0 {
+			if doc, err = applyPatch(doc, patchDoc); err != nil {
+				return err
+			}
+			if _, err := decodeGeneric(doc, stored.decode); err != nil {
+				return fmt.Errorf("the patch does not fit a %s %s", kind, err.Error())
+			}
+		}
+		for _, a := range assignments {
+			if err := assign(doc, a, stored.decode); err != nil {
+				return err
+			}
+		}
+		if metadataName(doc) != objectName {
+			return model.ValidationError{{Field: "metadata.name", Message: "cannot be changed"}}
+		}
+		updated, err := decodeGeneric(doc, stored.decode)
+		if err != nil {
+			return err
+		}
+		if err := stored.validate(updated); err != nil {
+			return err
+		}
+		stored.put(appTemp, name, updated)
+		return secret.SealTemplate(previous, appTemp)
+	})
+}
+
+func metadataName(doc map[string]interface{}) interface{} {
+	if meta, ok := doc["metadata"].(map[string]interface{}); ok {
+		return meta["name"]
+	}
+	return nil
+}
+
+func applyPatch(doc map[string]interface{}, patchDoc []byte) (map[string]interface{}, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(patchDoc), []byte("[")) {
+		var ops []patch.Operation
+		if err := json.Unmarshal(patchDoc, &ops); err != nil {
+			return nil, fmt.Errorf("failed to read json patch %s", err.Error())
+		}
+		patched, err := patch.ApplyJSON(doc, ops)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := patched.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the json patch replaced the object with a value")
+		}
+		return object, nil
+	}
+	var mergePatch interface{}
+	if err := json.Unmarshal(patchDoc, &mergePatch); err != nil {
+		return nil, fmt.Errorf("failed to read merge patch %s", err.Error())
+	}
+	object, ok := patch.Merge(doc, mergePatch).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("a merge patch must be an object")
+	}
+	return object, nil
+}
+
+// assign applies path=value or path-. The value is tried as json first, so numbers, booleans, objects and lists
+// keep their type, and as a string when the object does not accept that. Quantities and int-or-string fields take
+// either so 500m, 2Gi, 8080 and http all land as the field expects
+func assign(doc map[string]interface{}, assignment string, decode func([]byte) (interface{}, error)) error {
+	keyVal := splitAssignment(assignment)
+	if len(keyVal) == 1 {
+		if !strings.HasSuffix(assignment, "-") {
+			return fmt.Errorf("expected <path>=<value> or <path>- but got %s", assignment)
+		}
+		return patch.Remove(doc, strings.TrimSuffix(assignment, "-"))
+	}
+	path, raw := keyVal[0], keyVal[1]
+	candidates := []interface{}{raw}
+	var typed interface{}
+	if err := json.Unmarshal([]byte(raw), &typed); err == nil {
+		if _, isString := typed.(string); !isString {
+			candidates = []interface{}{typed, raw}
+		}
+	}
+	var lastErr error
+	for _, value := range candidates {
+		if err := patch.Set(doc, path, value); err != nil {
+			return err
+		}
+		if _, lastErr = decodeGeneric(doc, decode); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not fit %s %s", raw, path, lastErr.Error())
+}
+
+// splitAssignment splits path=value on the first = outside of a [key=value] selector
+func splitAssignment(assignment string) []string {
+	depth := 0
+	for i, r := range assignment {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '=':
+			if depth == 0 {
+				return []string{assignment[:i], assignment[i+1:]}
+			}
+		}
+	}
+	return []string{assignment}
+}
+
+func toGeneric(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	generic := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return generic, decoder.Decode(&generic)
+}
+
+func decodeGeneric(doc map[string]interface{}, decode func([]byte) (interface{}, error)) (interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
@@ -0,0 +1,158 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/maleck13/templator/cmd"
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/service"
+	"github.com/urfave/cli"
+)
+
+const DEFAULT_SNAPSHOT_DIR = "./snapshots"
+
+func VerifyCmd() cli.Command {
+	return cli.Command{
+		Name:         "verify",
+		ArgsUsage:    "<template>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Usage:        "verify <template> --nodes=1,3,5 --snapshots=./snapshots --update",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "nodes",
+				Value: "1,3,5",
+				Usage: "--nodes=1,3,5 the node counts to generate for, each with and without storage",
+			},
+			cli.BoolFlag{
+				Name:  "nodeSelector",
+				Usage: "--nodeSelector keeps node selectors in every generation",
+			},
+			cli.StringFlag{
+				Name:  "snapshots",
+				Value: DEFAULT_SNAPSHOT_DIR,
+				Usage: "--snapshots=<dir> where the snapshot files are kept",
+			},
+			cli.BoolFlag{
+				Name:  "update",
+				Usage: "--update rewrites the snapshots with the current output",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if len(context.Args()) != 1 {
+				return cli.NewExitError("expected one arg "+context.Command.ArgsUsage, 1)
+			}
+			matrix, err := optionMatrix(context.String("nodes"), context.Bool("nodeSelector"))
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			dir := filepath.Join(context.String("snapshots"), context.Args()[0])
+			drifted, err := VerifyAction(context.Args()[0], dir, matrix, context.Bool("update"))
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			if drifted {
+				return cli.NewExitError("generated output differs from the snapshots in "+dir+" run with --update to accept the changes", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// Snapshot is one entry of the option matrix and the file its output is compared against
+type Snapshot struct {
+	Name    string
+	Options generate.Options
+}
+
+func optionMatrix(nodes string, nodeSelector bool) ([]Snapshot, error) {
+	var matrix []Snapshot
+	for _, n := range strings.Split(nodes, ",") {
+		count, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse node count %s", n)
+		}
+		for _, storage := range []bool{false, true} {
+			name := fmt.Sprintf("nodes-%d", count)
+			if storage {
+				name += "-storage"
+			}
+			matrix = append(matrix, Snapshot{
+				Name:    name + ".json",
+				Options: generate.Options{Nodes: count, Storage: storage, NodeSelector: nodeSelector},
+			})
+		}
+	}
+	return matrix, nil
+}
+
+// VerifyAction generates the template for every snapshot and reports the objects that changed. With update the
+// snapshots are rewritten instead. It returns true if any snapshot drifted
+func VerifyAction(templateName, dir string, matrix []Snapshot, update bool) (bool, error) {
+	appTemplate, err := service.NewTemplateService("local").GetTemplate(templateName)
+	if err != nil {
+		return false, err
+	}
+	if nil == appTemplate {
+		return false, fmt.Errorf("no template named %s", templateName)
+	}
+	if update {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return false, err
+		}
+	}
+	drifted := false
+	for _, s := range matrix {
+		result, err := generate.Generate(appTemplate, s.Options)
+		if err != nil {
+			return false, err
+		}
+		generated, err := json.MarshalIndent(result.Template, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		generated = append(generated, '\n')
+		file := filepath.Join(dir, s.Name)
+		if update {
+			if err := ioutil.WriteFile(file, generated, 0644); err != nil {
+				return false, err
+			}
+			fmt.Println("updated " + file)
+			continue
+		}
+		existing, err := ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			fmt.Printf("%s: missing snapshot\n", s.Name)
+			drifted = true
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(existing, generated) {
+			fmt.Printf("%s: ok\n", s.Name)
+			continue
+		}
+		changes, err := generate.DiffTemplates(existing, generated)
+		if err != nil {
+			return false, fmt.Errorf("%s: %s", file, err.Error())
+		}
+		if len(changes) == 0 {
+			//only formatting differs
+			fmt.Printf("%s: ok\n", s.Name)
+			continue
+		}
+		drifted = true
+		fmt.Printf("%s: drifted\n", s.Name)
+		for _, c := range changes {
+			fmt.Println("  " + c.String())
+		}
+	}
+	return drifted, nil
+}
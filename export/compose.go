@@ -0,0 +1,251 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+// COMPOSE_VERSION is the compose file version written. 2.4 is the last version with cpus and mem_limit outside of swarm
+const COMPOSE_VERSION = "2.4"
+
+type composeFile struct {
+	Version  string                     `json:"version"`
+	Services map[string]*composeService `json:"services"`
+	Volumes  map[string]*composeVolume  `json:"volumes,omitempty"`
+}
+
+type composeService struct {
+	Image          string                     `json:"image"`
+	Entrypoint     []string                   `json:"entrypoint,omitempty"`
+	Command        []string                   `json:"command,omitempty"`
+	WorkingDir     string                     `json:"working_dir,omitempty"`
+	Environment    map[string]string          `json:"environment,omitempty"`
+	Ports          []string                   `json:"ports,omitempty"`
+	Expose         []string                   `json:"expose,omitempty"`
+	Volumes        []string                   `json:"volumes,omitempty"`
+	CPUs           float64                    `json:"cpus,omitempty"`
+	MemLimit       int64                      `json:"mem_limit,omitempty"`
+	MemReservation int64                      `json:"mem_reservation,omitempty"`
+	Scale          int                        `json:"scale,omitempty"`
+	Labels         map[string]string          `json:"labels,omitempty"`
+	Networks       map[string]*composeNetwork `json:"networks,omitempty"`
+}
+
+type composeNetwork struct {
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type composeVolume struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Compose returns a compose file running every container of the generated deployment configs as a compose service.
+// Services are reached by the name of the kubernetes services selecting them and their ports are published on the
+// host. Volumes are always kept as named volumes cost nothing locally. Parameter references become compose variables
+// defaulting to the parameter value
+func Compose(appTemp *model.ApplicationTemplate, opts generate.Options) ([]byte, []string, error) {
+	if opts.Nodes == 0 {
+		//a laptop is a single node
+		opts.Nodes = 1
+	}
+	//without storage the claims would be dropped and the data lost with the container
+	opts.Storage = true
+	//services are made from deployment configs whatever the target
+	opts.Target = generate.TARGET_OPENSHIFT
+	result, err := generate.Generate(appTemp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings := result.Warnings
+	var (
+		deployments []*model.DeploymentConfig
+		services    []*k8.Service
+	)
+	for _, obj := range result.Objects {
+		switch o := obj.(type) {
+		case *model.DeploymentConfig:
+			deployments = append(deployments, o)
+		case *k8.Service:
+			services = append(services, o)
+		case *model.Route:
+			warnings = append(warnings, fmt.Sprintf("route %s is not supported by compose, the ports of service %s are published on the host instead", o.Name, o.Spec.To.Name))
+		}
+	}
+
+	compose := &composeFile{Version: COMPOSE_VERSION, Services: map[string]*composeService{}}
+	hostPorts := make(map[string]string)
+	for _, dc := range deployments {
+		if nil == dc.Spec.Template {
+			continue
+		}
+		podSpec := dc.Spec.Template.Spec
+		if len(podSpec.NodeSelector) > 0 {
+			warnings = append(warnings, fmt.Sprintf("deployment %s has a node selector which compose ignores", dc.Name))
+		}
+		if len(podSpec.Containers) > 1 {
+			warnings = append(warnings, fmt.Sprintf("the containers of deployment %s become separate compose services and no longer share localhost", dc.Name))
+		}
+		var selecting []*k8.Service
+		for _, s := range services {
+			if selects(s.Spec.Selector, dc.Spec.Template.Labels) {
+				selecting = append(selecting, s)
+			}
+		}
+		claims := make(map[string]string)
+		for _, v := range podSpec.Volumes {
+			switch {
+			case v.PersistentVolumeClaim != nil:
+				claims[v.Name] = v.PersistentVolumeClaim.ClaimName
+				if nil == compose.Volumes {
+					compose.Volumes = map[string]*composeVolume{}
+				}
+				compose.Volumes[v.PersistentVolumeClaim.ClaimName] = &composeVolume{}
+			case v.HostPath != nil:
+				claims[v.Name] = v.HostPath.Path
+			case v.EmptyDir != nil:
+				claims[v.Name] = ""
+			default:
+				warnings = append(warnings, fmt.Sprintf("volume %s of deployment %s is not a claim, host path or empty dir and is not mounted", v.Name, dc.Name))
+			}
+		}
+		for _, c := range podSpec.Containers {
+			name := dc.Name
+			if len(podSpec.Containers) > 1 {
+				name = dc.Name + "-" + c.Name
+			}
+			service := &composeService{
+				Image:       c.Image,
+				Entrypoint:  c.Command,
+				Command:     c.Args,
+				WorkingDir:  c.WorkingDir,
+				Labels:      dc.Spec.Template.Labels,
+				Environment: map[string]string{},
+			}
+			if dc.Spec.Replicas > 1 {
+				service.Scale = dc.Spec.Replicas
+			}
+			for _, e := range c.Env {
+				if e.ValueFrom != nil {
+					warnings = append(warnings, fmt.Sprintf("env var %s of container %s is set from a reference which compose does not support", e.Name, name))
+					continue
+				}
+				service.Environment[e.Name] = e.Value
+			}
+			if q, ok := c.Resources.Limits[k8.ResourceCPU]; ok {
+				service.CPUs = float64(q.MilliValue()) / 1000
+			}
+			if q, ok := c.Resources.Limits[k8.ResourceMemory]; ok {
+				service.MemLimit = q.Value()
+			}
+			if q, ok := c.Resources.Requests[k8.ResourceMemory]; ok {
+				service.MemReservation = q.Value()
+			}
+			for _, m := range c.VolumeMounts {
+				source, ok := claims[m.Name]
+				if !ok {
+					continue
+				}
+				volume := m.MountPath
+				if "" != source {
+					volume = source + ":" + m.MountPath
+				}
+				if m.ReadOnly {
+					volume += ":ro"
+				}
+				service.Volumes = append(service.Volumes, volume)
+			}
+			aliases := []string{}
+			for _, s := range selecting {
+				aliases = append(aliases, s.Name)
+			}
+			for _, p := range c.Ports {
+				published := false
+				for _, s := range selecting {
+					for _, sp := range s.Spec.Ports {
+						if !targetsPort(sp.TargetPort, sp.Port, p) || sp.Protocol != p.Protocol && "" != sp.Protocol && "" != p.Protocol {
+							continue
+						}
+						mapping := fmt.Sprintf("%d:%d", sp.Port, p.ContainerPort)
+						if p.Protocol == k8.ProtocolUDP {
+							mapping += "/udp"
+						}
+						if other, used := hostPorts[fmt.Sprintf("%d/%s", sp.Port, p.Protocol)]; used {
+							warnings = append(warnings, fmt.Sprintf("port %d of service %s is already published for %s and is not published for %s", sp.Port, s.Name, other, name))
+							continue
+						}
+						hostPorts[fmt.Sprintf("%d/%s", sp.Port, p.Protocol)] = name
+						service.Ports = append(service.Ports, mapping)
+						published = true
+					}
+				}
+				if !published {
+					service.Expose = append(service.Expose, fmt.Sprintf("%d", p.ContainerPort))
+				}
+			}
+			if service.Scale > 1 && len(service.Ports) > 0 {
+				warnings = append(warnings, fmt.Sprintf("%s runs %d replicas but publishes host ports %s which only one of them can bind", name, service.Scale, strings.Join(service.Ports, ",")))
+			}
+			sort.Strings(aliases)
+			if len(aliases) > 0 && len(podSpec.Containers) == 1 {
+				service.Networks = map[string]*composeNetwork{"default": {Aliases: aliases}}
+			}
+			compose.Services[name] = service
+		}
+	}
+
+	generic, err := toGeneric(compose)
+	if err != nil {
+		return nil, nil, err
+	}
+	defaults := make(map[string]string)
+	for _, p := range appTemp.Parameters {
+		defaults[p.Name] = p.Value
+	}
+	replaceStrings(generic, func(s string) string {
+		return composeVariables(s, defaults)
+	})
+	data, err := yaml.Marshal(generic)
+	return data, warnings, err
+}
+
+// composeVariables turns ${PARAM} and ${{PARAM}} into compose variables with the parameter value as default and
+// escapes any other $ so that compose does not interpolate it
+func composeVariables(s string, defaults map[string]string) string {
+	var out []string
+	last := 0
+	for _, loc := range parameterRef.FindAllStringSubmatchIndex(s, -1) {
+		out = append(out, strings.Replace(s[last:loc[0]], "$", "$$", -1))
+		param := s[loc[2]:loc[3]]
+		value, ok := defaults[param]
+		switch {
+		case !ok:
+			out = append(out, strings.Replace(s[loc[0]:loc[1]], "$", "$$", -1))
+		case "" == value:
+			out = append(out, "${"+param+"}")
+		default:
+			out = append(out, "${"+param+":-"+strings.Replace(value, "$", "$$", -1)+"}")
+		}
+		last = loc[1]
+	}
+	out = append(out, strings.Replace(s[last:], "$", "$$", -1))
+	return strings.Join(out, "")
+}
+
+// targetsPort reports whether a service port with the given target port sends traffic to the container port
+func targetsPort(target intstr.IntOrString, servicePort int32, p k8.ContainerPort) bool {
+	switch {
+	case target.Type == intstr.String && "" != target.StrVal:
+		return target.StrVal == p.Name
+	case target.Type == intstr.Int && target.IntVal != 0:
+		return target.IntVal == p.ContainerPort
+	}
+	//no target port means the service port
+	return servicePort == p.ContainerPort
+}
@@ -0,0 +1,81 @@
+// Package export writes an ApplicationTemplate in formats other than an OpenShift template
+package export
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// parameterRef matches the ${PARAM} and ${{PARAM}} references OpenShift substitutes when processing a template
+var parameterRef = regexp.MustCompile(`\$\{\{?([A-Za-z0-9_]+)\}?\}`)
+
+// toGeneric converts an object to the maps and slices of its json form
+func toGeneric(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	generic := make(map[string]interface{})
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// replaceStrings calls replace on every string value below v and returns the new value
+func replaceStrings(v interface{}, replace func(string) string) interface{} {
+	switch value := v.(type) {
+	case string:
+		return replace(value)
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = replaceStrings(item, replace)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = replaceStrings(item, replace)
+		}
+	}
+	return v
+}
+
+// field returns the map found by following path from obj or nil
+func field(obj map[string]interface{}, path ...string) map[string]interface{} {
+	current := obj
+	for _, p := range path {
+		next, ok := current[p].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// fileName returns a file name for an object made of its kind and name
+func fileName(kind, name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+	return strings.ToLower(kind) + "-" + strings.Trim(name, "-") + ".yaml"
+}
+
+// selects reports whether a non empty selector matches labels
+func selects(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+)
+
+type graphNode struct {
+	id      string
+	kind    string
+	name    string
+	missing bool
+}
+
+type graphEdge struct {
+	from  *graphNode
+	to    *graphNode
+	label string
+}
+
+type objectGraph struct {
+	nodes []*graphNode
+	index map[string]*graphNode
+	edges []graphEdge
+}
+
+func (g *objectGraph) node(kind, name string, missing bool) *graphNode {
+	key := kind + "/" + name
+	if n, ok := g.index[key]; ok {
+		return n
+	}
+	n := &graphNode{id: fmt.Sprintf("n%d", len(g.nodes)), kind: kind, name: name, missing: missing}
+	g.nodes = append(g.nodes, n)
+	g.index[key] = n
+	return n
+}
+
+func (g *objectGraph) edge(from, to *graphNode, label string) {
+	g.edges = append(g.edges, graphEdge{from: from, to: to, label: label})
+}
+
+// Graph renders the objects generated from appTemp and how they reference each other as a dot or mermaid graph:
+// routes to the services they point at, services to the deployment configs they select, deployment configs to the
+// claims they mount and autoscalers to the configs they scale. References to objects that are not generated are drawn
+// as missing nodes and warned about
+func Graph(appTemp *model.ApplicationTemplate, opts generate.Options, format string) ([]byte, []string, error) {
+	if "dot" != format && "mermaid" != format {
+		return nil, nil, fmt.Errorf("unsupported graph format %s expected dot|mermaid", format)
+	}
+	//claims are only generated with storage and they are part of what the graph shows
+	opts.Storage = true
+	result, err := generate.Generate(appTemp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings := result.Warnings
+	g := &objectGraph{index: make(map[string]*graphNode)}
+	var (
+		deployments []*model.DeploymentConfig
+		services    []*k8.Service
+		routes      []*model.Route
+		autoscalers []*autoscaling.HorizontalPodAutoscaler
+	)
+	for _, obj := range result.Objects {
+		g.node(generate.ObjectKind(obj), generate.ObjectName(obj), false)
+		switch o := obj.(type) {
+		case *model.DeploymentConfig:
+			deployments = append(deployments, o)
+		case *k8.Service:
+			services = append(services, o)
+		case *model.Route:
+			routes = append(routes, o)
+		case *autoscaling.HorizontalPodAutoscaler:
+			autoscalers = append(autoscalers, o)
+		}
+	}
+
+	for _, r := range routes {
+		from := g.index["Route/"+r.Name]
+		to, ok := g.index["Service/"+r.Spec.To.Name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("route %s points at service %s which does not exist", r.Name, r.Spec.To.Name))
+			to = g.node("Service", r.Spec.To.Name, true)
+		}
+		g.edge(from, to, "to")
+	}
+	for _, s := range services {
+		if len(s.Spec.Selector) == 0 {
+			continue
+		}
+		from := g.index["Service/"+s.Name]
+		matched := false
+		for _, dc := range deployments {
+			if dc.Spec.Template != nil && selects(s.Spec.Selector, dc.Spec.Template.Labels) {
+				g.edge(from, g.index["DeploymentConfig/"+dc.Name], "selects")
+				matched = true
+			}
+		}
+		if !matched {
+			selector := selectorString(s.Spec.Selector)
+			warnings = append(warnings, fmt.Sprintf("service %s selector %s matches no deployment", s.Name, selector))
+			g.edge(from, g.node("Pods", selector, true), "selects")
+		}
+	}
+	for _, a := range autoscalers {
+		ref := a.Spec.ScaleTargetRef
+		g.edge(g.index["HorizontalPodAutoscaler/"+a.Name], g.node(ref.Kind, ref.Name, true), "scales")
+	}
+	for _, dc := range deployments {
+		if nil == dc.Spec.Template {
+			continue
+		}
+		from := g.index["DeploymentConfig/"+dc.Name]
+		for _, v := range dc.Spec.Template.Spec.Volumes {
+			if nil == v.PersistentVolumeClaim {
+				continue
+			}
+			claim := v.PersistentVolumeClaim.ClaimName
+			to, ok := g.index["PersistentVolumeClaim/"+claim]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("deployment %s mounts claim %s which does not exist", dc.Name, claim))
+				to = g.node("PersistentVolumeClaim", claim, true)
+			}
+			g.edge(from, to, "mounts")
+		}
+	}
+
+	if "dot" == format {
+		return g.dot(appTemp.Name), warnings, nil
+	}
+	return g.mermaid(), warnings, nil
+}
+
+func (g *objectGraph) dot(name string) []byte {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "digraph %q {\n", name)
+	out.WriteString("  rankdir=LR;\n")
+	for _, n := range g.nodes {
+		label := n.kind + "\\n" + n.name
+		if n.missing {
+			fmt.Fprintf(&out, "  %s [label=\"%s\\n(missing)\" shape=box style=dashed color=red];\n", n.id, dotEscape(label))
+			continue
+		}
+		fmt.Fprintf(&out, "  %s [label=\"%s\" shape=box];\n", n.id, dotEscape(label))
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&out, "  %s -> %s [label=%q];\n", e.from.id, e.to.id, e.label)
+	}
+	out.WriteString("}\n")
+	return out.Bytes()
+}
+
+func (g *objectGraph) mermaid() []byte {
+	var out bytes.Buffer
+	out.WriteString("graph LR\n")
+	out.WriteString("  classDef missing stroke:#f00,stroke-dasharray:5 5;\n")
+	for _, n := range g.nodes {
+		label := n.kind + "<br/>" + n.name
+		if n.missing {
+			fmt.Fprintf(&out, "  %s[\"%s<br/>(missing)\"]:::missing\n", n.id, mermaidEscape(label))
+			continue
+		}
+		fmt.Fprintf(&out, "  %s[\"%s\"]\n", n.id, mermaidEscape(label))
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&out, "  %s -->|%s| %s\n", e.from.id, e.label, e.to.id)
+	}
+	return out.Bytes()
+}
+
+func selectorString(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for k, v := range selector {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func dotEscape(s string) string {
+	return strings.Replace(s, `"`, `\"`, -1)
+}
+
+func mermaidEscape(s string) string {
+	return strings.Replace(s, `"`, "#quot;", -1)
+}
@@ -0,0 +1,294 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+)
+
+const (
+	helmNodeIndex = "{{ $i }}"
+	helmRangeNode = "{{- range $i, $e := until (int .Values.nodes) }}\n---\n"
+)
+
+var (
+	helmIdentifier      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	helmConditionalLine = regexp.MustCompile(`(?m)^( *)([^\s:'"-][^\s:'"]*): ['"]?__HELM_IF_(\d+)__['"]?$`)
+)
+
+// HelmChart writes a chart for appTemp to dir. The node count, storage and node selector switches become chart values
+// defaulting to opts, parameters become values and ${PARAM} references become {{ .Values.PARAM }}
+func HelmChart(appTemp *model.ApplicationTemplate, opts generate.Options, dir string) ([]string, error) {
+	var warnings []string
+	chartTemp, err := appTemp.Copy()
+	if err != nil {
+		return nil, err
+	}
+	// per node objects are generated once with the node index left in their names and expanded by a range in the chart
+	perNode := make(map[string]bool)
+	perNodeClaims := make(map[string]bool)
+	setClaims := make(map[string]bool)
+	// equalToNodes holds the names of the generated controllers whose replicas are the node count
+	equalToNodes := make(map[string]bool)
+	for _, dc := range chartTemp.DeploymentConfigs {
+		//DaemonSets and StatefulSets are single controllers that run the per node pods themselves
+		if generate.TARGET_KUBERNETES == opts.Target && "" != dc.Spec.WorkloadKind {
+			if model.WorkloadKind_StatefulSet == dc.Spec.WorkloadKind && (dc.Spec.ReplicaStrategy == model.ReplicationStrategy_EqualToNodes || dc.Spec.DeploymentStrategy == model.DeploymentStrategy_PerNodeConfig) {
+				equalToNodes[generate.WorkloadName(dc.Name)] = true
+			}
+			dc.Spec.ReplicaStrategy = ""
+			if dc.Spec.Template != nil {
+				for _, v := range dc.Spec.Template.Spec.Volumes {
+					if v.PersistentVolumeClaim != nil {
+						setClaims[v.PersistentVolumeClaim.ClaimName] = true
+					}
+				}
+			}
+			continue
+		}
+		if dc.Spec.ReplicaStrategy == model.ReplicationStrategy_EqualToNodes {
+			dc.Spec.ReplicaStrategy = ""
+			equalToNodes[dc.Name] = true
+		}
+		if dc.Spec.DeploymentStrategy != model.DeploymentStrategy_PerNodeConfig {
+			continue
+		}
+		dc.Spec.DeploymentStrategy = model.DeploymentStrategy_SingleConfig
+		if equalToNodes[dc.Name] {
+			delete(equalToNodes, dc.Name)
+			equalToNodes[helmNodeName(dc.Name)] = true
+		}
+		dc.Name = helmNodeName(dc.Name)
+		perNode[dc.Name] = true
+		if generate.TARGET_KUBERNETES == opts.Target {
+			generate.SetNodeLabel(dc, helmNodeIndex)
+		}
+		if dc.Spec.Template != nil {
+			for _, v := range dc.Spec.Template.Spec.Volumes {
+				if v.PersistentVolumeClaim != nil {
+					perNodeClaims[v.PersistentVolumeClaim.ClaimName] = true
+					v.PersistentVolumeClaim.ClaimName = helmNodeName(v.PersistentVolumeClaim.ClaimName)
+				}
+			}
+		}
+	}
+	for _, pvc := range chartTemp.PersistentVolumes {
+		//claims mounted by per node configs get the index in their name as the configs mount them by it, those of
+		//StatefulSets keep theirs to be found as claim templates
+		if !setClaims[pvc.Name] && (strings.Contains(pvc.Name, "%d") || perNodeClaims[pvc.Name]) {
+			pvc.Name = helmNodeName(pvc.Name)
+			perNode[pvc.Name] = true
+		}
+	}
+
+	chartOpts := opts
+	chartOpts.Nodes = 1
+	chartOpts.Storage = true
+	chartOpts.NodeSelector = true
+	result, err := generate.Generate(chartTemp, chartOpts)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, result.Warnings...)
+
+	params := make(map[string]*model.Parameter)
+	for _, p := range appTemp.Parameters {
+		params[p.Name] = p
+		if "" != p.Generate {
+			warnings = append(warnings, fmt.Sprintf("parameter %s is generated by openshift from %s, set a value for it in values.yaml", p.Name, p.From))
+		}
+	}
+
+	templatesDir := filepath.Join(dir, "templates")
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"), helmChartFile(appTemp), 0644); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "values.yaml"), helmValuesFile(appTemp, opts), 0644); err != nil {
+		return nil, err
+	}
+
+	for _, obj := range result.Objects {
+		kind, name := generate.ObjectKind(obj), generate.ObjectName(obj)
+		generic, err := toGeneric(obj)
+		if err != nil {
+			return nil, err
+		}
+		cf := &helmFile{}
+		if perNode[name] {
+			cf.root = "$"
+		}
+		replaceStrings(generic, func(s string) string {
+			if m := parameterRef.FindStringSubmatch(s); m != nil && m[0] == s && strings.HasPrefix(s, "${{") && params[m[1]] != nil {
+				//${{PARAM}} is substituted without quoting
+				return cf.raw("{{ " + cf.value(m[1]) + " }}")
+			}
+			return parameterRef.ReplaceAllStringFunc(s, func(ref string) string {
+				param := parameterRef.FindStringSubmatch(ref)[1]
+				if nil == params[param] {
+					return ref
+				}
+				return "{{ " + cf.value(param) + " }}"
+			})
+		})
+		switch kind {
+		case "DeploymentConfig", "Deployment", "DaemonSet", "StatefulSet":
+			if spec := field(generic, "spec"); spec != nil {
+				if equalToNodes[name] {
+					spec["replicas"] = cf.raw("{{ " + cf.value("nodes") + " }}")
+				}
+				cf.conditionalField(spec, "volumeClaimTemplates", cf.value("storage"))
+			}
+			if podSpec := field(generic, "spec", "template", "spec"); podSpec != nil {
+				cf.conditionalField(podSpec, "volumes", cf.value("storage"))
+				cf.conditionalField(podSpec, "nodeSelector", cf.value("nodeSelector"))
+				containers, _ := podSpec["containers"].([]interface{})
+				for _, c := range containers {
+					if container, ok := c.(map[string]interface{}); ok {
+						cf.conditionalField(container, "volumeMounts", cf.value("storage"))
+					}
+				}
+			}
+		}
+		content, err := cf.render(generic)
+		if err != nil {
+			return nil, err
+		}
+		if perNode[name] {
+			content = append([]byte(helmRangeNode), content...)
+			content = append(content, []byte("{{- end }}\n")...)
+		}
+		if "PersistentVolumeClaim" == kind {
+			content = append([]byte("{{- if .Values.storage }}\n"), content...)
+			content = append(content, []byte("{{- end }}\n")...)
+		}
+		file := filepath.Join(templatesDir, fileName(kind, strings.Replace(name, helmNodeIndex, "", -1)))
+		if err := ioutil.WriteFile(file, content, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// helmNodeName puts the range index into a per node name the same way generate puts the node number in
+func helmNodeName(name string) string {
+	if strings.Contains(name, "%d") {
+		return strings.Replace(name, "%d", helmNodeIndex, -1)
+	}
+	return name + "-" + helmNodeIndex
+}
+
+// helmFile collects the template actions of a single chart file. They are put into the object as markers and
+// swapped in once the object has been written as yaml
+type helmFile struct {
+	root         string
+	conditions   []string
+	conditionals []interface{}
+	raws         []string
+}
+
+func (hf *helmFile) value(name string) string {
+	if helmIdentifier.MatchString(name) {
+		return fmt.Sprintf("%s.Values.%s", hf.root, name)
+	}
+	return fmt.Sprintf("(index %s.Values %s)", hf.root, strconv.Quote(name))
+}
+
+// raw returns a marker that is replaced with expr unquoted
+func (hf *helmFile) raw(expr string) string {
+	hf.raws = append(hf.raws, expr)
+	return fmt.Sprintf("__HELM_RAW_%d__", len(hf.raws)-1)
+}
+
+// conditionalField wraps the field of obj in an if block on condition
+func (hf *helmFile) conditionalField(obj map[string]interface{}, key, condition string) {
+	v, ok := obj[key]
+	if !ok {
+		return
+	}
+	hf.conditions = append(hf.conditions, condition)
+	hf.conditionals = append(hf.conditionals, v)
+	obj[key] = fmt.Sprintf("__HELM_IF_%d__", len(hf.conditionals)-1)
+}
+
+func (hf *helmFile) render(obj map[string]interface{}) ([]byte, error) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var renderErr error
+	data = helmConditionalLine.ReplaceAllFunc(data, func(line []byte) []byte {
+		m := helmConditionalLine.FindSubmatch(line)
+		i, _ := strconv.Atoi(string(m[3]))
+		block, err := yaml.Marshal(hf.conditionals[i])
+		if err != nil {
+			renderErr = err
+			return line
+		}
+		indent := string(m[1])
+		var out bytes.Buffer
+		fmt.Fprintf(&out, "{{- if %s }}\n%s%s:\n", hf.conditions[i], indent, m[2])
+		for _, l := range strings.Split(strings.TrimRight(string(block), "\n"), "\n") {
+			out.WriteString(indent + "  " + l + "\n")
+		}
+		out.WriteString("{{- end }}")
+		return out.Bytes()
+	})
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	content := string(data)
+	for i, expr := range hf.raws {
+		marker := fmt.Sprintf("__HELM_RAW_%d__", i)
+		for _, quoted := range []string{"'" + marker + "'", `"` + marker + `"`, marker} {
+			content = strings.Replace(content, quoted, expr, -1)
+		}
+	}
+	return []byte(content), nil
+}
+
+func helmChartFile(appTemp *model.ApplicationTemplate) []byte {
+	var out bytes.Buffer
+	out.WriteString("apiVersion: v1\n")
+	fmt.Fprintf(&out, "name: %s\n", appTemp.Name)
+	out.WriteString("version: 0.1.0\n")
+	if desc := appTemp.Annotations["description"]; "" != desc {
+		fmt.Fprintf(&out, "description: %s\n", strconv.Quote(desc))
+	}
+	return out.Bytes()
+}
+
+func helmValuesFile(appTemp *model.ApplicationTemplate, opts generate.Options) []byte {
+	var out bytes.Buffer
+	out.WriteString("# the number of nodes, sets the number of per node deployments and #EqualToNodes replicas\n")
+	fmt.Fprintf(&out, "nodes: %d\n", opts.Nodes)
+	out.WriteString("# keep deployment volumes and create the persistent volume claims\n")
+	fmt.Fprintf(&out, "storage: %t\n", opts.Storage)
+	out.WriteString("# keep deployment node selectors\n")
+	fmt.Fprintf(&out, "nodeSelector: %t\n", opts.NodeSelector)
+	for _, p := range appTemp.Parameters {
+		out.WriteString("\n")
+		if desc := p.Description; "" != desc {
+			fmt.Fprintf(&out, "# %s\n", desc)
+		}
+		if "" != p.Generate {
+			fmt.Fprintf(&out, "# generated by openshift from %s\n", p.From)
+		}
+		if p.Required {
+			out.WriteString("# required\n")
+		}
+		fmt.Fprintf(&out, "%s: %s\n", strconv.Quote(p.Name), strconv.Quote(p.Value))
+	}
+	return out.Bytes()
+}
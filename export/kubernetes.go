@@ -0,0 +1,32 @@
+package export
+
+import (
+	"encoding/json"
+
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+)
+
+// Kubernetes returns the objects generated for the kubernetes target as a v1 List that kubectl can create. Kubernetes
+// has no templates so parameter references are replaced with the parameter values
+func Kubernetes(appTemp *model.ApplicationTemplate, opts generate.Options) ([]byte, []string, error) {
+	opts.Target = generate.TARGET_KUBERNETES
+	objects, warnings, err := resolvedObjects(appTemp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]interface{}, 0, len(objects))
+	for _, o := range objects {
+		items = append(items, o.object)
+	}
+	list := map[string]interface{}{
+		"kind":       "List",
+		"apiVersion": "v1",
+		"items":      items,
+	}
+	data, err := json.MarshalIndent(list, "", " ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(data, '\n'), warnings, nil
+}
@@ -0,0 +1,336 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+)
+
+// strategicKinds are the kinds kustomize knows the patch strategy of. Other kinds are patched as json merge patches
+// where lists are always replaced so they must not get a $patch: replace directive
+var strategicKinds = map[string]bool{
+	"Service":                 true,
+	"PersistentVolumeClaim":   true,
+	"Pod":                     true,
+	"Secret":                  true,
+	"ConfigMap":               true,
+	"Deployment":              true,
+	"DaemonSet":               true,
+	"StatefulSet":             true,
+	"HorizontalPodAutoscaler": true,
+}
+
+// Overlay is a kustomize overlay generated with different options to the base
+type Overlay struct {
+	Name    string
+	Options generate.Options
+}
+
+type kustomization struct {
+	APIVersion            string   `json:"apiVersion"`
+	Kind                  string   `json:"kind"`
+	Namespace             string   `json:"namespace,omitempty"`
+	Resources             []string `json:"resources,omitempty"`
+	PatchesStrategicMerge []string `json:"patchesStrategicMerge,omitempty"`
+}
+
+type kustomizeObject struct {
+	kind   string
+	name   string
+	object map[string]interface{}
+}
+
+// Kustomize writes one manifest per object generated with base plus a kustomization.yaml to dir. When overlays are
+// given the base goes to dir/base and each overlay to dir/overlays/<name> with strategic merge patches for the
+// objects that differ from the base. An overlay in another namespace sets it in its kustomization.yaml rather than in
+// its patches. The files of an earlier export to dir are removed first so objects that are gone leave nothing behind
+func Kustomize(appTemp *model.ApplicationTemplate, base generate.Options, overlays []Overlay, dir string) ([]string, error) {
+	if err := removeExport(dir); err != nil {
+		return nil, err
+	}
+	baseDir := dir
+	if len(overlays) > 0 {
+		baseDir = filepath.Join(dir, "base")
+	}
+	baseObjects, baseWarnings, err := resolvedObjects(appTemp, base)
+	if err != nil {
+		return nil, err
+	}
+	warnings := append([]string{}, baseWarnings...)
+	baseKustomization := kustomization{}
+	for _, o := range baseObjects {
+		file := fileName(o.kind, o.name)
+		if err := writeYAML(baseDir, file, o.object); err != nil {
+			return nil, err
+		}
+		baseKustomization.Resources = append(baseKustomization.Resources, file)
+	}
+	if err := writeKustomization(baseDir, baseKustomization); err != nil {
+		return nil, err
+	}
+
+	for _, overlay := range overlays {
+		// the namespace transformer of the overlay moves every object so the patches are diffed in the base namespace
+		opts := overlay.Options
+		opts.Namespace = base.Namespace
+		objects, overlayWarnings, err := resolvedObjects(appTemp, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range overlayWarnings {
+			if containsString(baseWarnings, w) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("overlay %s: %s", overlay.Name, w))
+		}
+		overlayDir := filepath.Join(dir, "overlays", overlay.Name)
+		k := kustomization{Resources: []string{"../../base"}}
+		if overlay.Options.Namespace != base.Namespace {
+			if "" == overlay.Options.Namespace {
+				warnings = append(warnings, fmt.Sprintf("overlay %s has no namespace but kustomize cannot remove the base namespace %s so it is kept", overlay.Name, base.Namespace))
+			}
+			k.Namespace = overlay.Options.Namespace
+		}
+		inBase := make(map[string]kustomizeObject, len(baseObjects))
+		for _, o := range baseObjects {
+			inBase[o.kind+"/"+o.name] = o
+		}
+		inOverlay := make(map[string]bool, len(objects))
+		for _, o := range objects {
+			inOverlay[o.kind+"/"+o.name] = true
+			baseObject, ok := inBase[o.kind+"/"+o.name]
+			if !ok {
+				file := fileName(o.kind, o.name)
+				if err := writeYAML(overlayDir, file, o.object); err != nil {
+					return nil, err
+				}
+				k.Resources = append(k.Resources, file)
+				continue
+			}
+			patch := strategicPatch(baseObject.object, o.object, strategicKinds[o.kind])
+			if nil == patch {
+				continue
+			}
+			file := "patch-" + fileName(o.kind, o.name)
+			if err := writeYAML(overlayDir, file, patch); err != nil {
+				return nil, err
+			}
+			k.PatchesStrategicMerge = append(k.PatchesStrategicMerge, file)
+		}
+		for _, o := range baseObjects {
+			if inOverlay[o.kind+"/"+o.name] {
+				continue
+			}
+			patch := patchHeader(o.object)
+			patch["$patch"] = "delete"
+			file := "delete-" + fileName(o.kind, o.name)
+			if err := writeYAML(overlayDir, file, patch); err != nil {
+				return nil, err
+			}
+			k.PatchesStrategicMerge = append(k.PatchesStrategicMerge, file)
+		}
+		if err := writeKustomization(overlayDir, k); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// resolvedObjects generates the objects of appTemp with parameter references replaced by the parameter values for
+// formats that have no parameters of their own
+func resolvedObjects(appTemp *model.ApplicationTemplate, opts generate.Options) ([]kustomizeObject, []string, error) {
+	result, err := generate.Generate(appTemp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings := result.Warnings
+	values := make(map[string]string)
+	for _, p := range appTemp.Parameters {
+		if "" != p.Value {
+			values[p.Name] = p.Value
+		}
+	}
+	unset := make(map[string]bool)
+	var objects []kustomizeObject
+	for _, obj := range result.Objects {
+		generic, err := toGeneric(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		replaceStrings(generic, func(s string) string {
+			return parameterRef.ReplaceAllStringFunc(s, func(ref string) string {
+				param := parameterRef.FindStringSubmatch(ref)[1]
+				if value, ok := values[param]; ok {
+					return value
+				}
+				unset[param] = true
+				return ref
+			})
+		})
+		objects = append(objects, kustomizeObject{kind: generate.ObjectKind(obj), name: generate.ObjectName(obj), object: generic})
+	}
+	var params []string
+	for p := range unset {
+		params = append(params, p)
+	}
+	sort.Strings(params)
+	for _, p := range params {
+		warnings = append(warnings, fmt.Sprintf("parameter %s has no value, references to it are left as ${%s}", p, p))
+	}
+	return objects, warnings, nil
+}
+
+// strategicPatch returns a patch turning before into after or nil when they are the same. The patch is addressed to
+// before as that is the object kustomize applies it to
+func strategicPatch(before, after map[string]interface{}, replaceLists bool) map[string]interface{} {
+	diff := mergePatch(before, after, replaceLists)
+	if nil == diff {
+		return nil
+	}
+	patch := patchHeader(before)
+	for k, v := range diff {
+		if meta, ok := v.(map[string]interface{}); ok && "metadata" == k {
+			for mk, mv := range meta {
+				patch["metadata"].(map[string]interface{})[mk] = mv
+			}
+			continue
+		}
+		patch[k] = v
+	}
+	return patch
+}
+
+// mergePatch returns the fields of after that differ from before with null for removed fields, or nil when they are
+// the same. Changed lists of objects get a $patch: replace directive when replaceLists is set as strategic merge
+// would otherwise merge them by key
+func mergePatch(before, after map[string]interface{}, replaceLists bool) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for k, a := range after {
+		b, ok := before[k]
+		if ok && reflect.DeepEqual(a, b) {
+			continue
+		}
+		afterMap, afterIsMap := a.(map[string]interface{})
+		beforeMap, beforeIsMap := b.(map[string]interface{})
+		if afterIsMap && beforeIsMap {
+			if sub := mergePatch(beforeMap, afterMap, replaceLists); nil != sub {
+				patch[k] = sub
+			}
+			continue
+		}
+		if list, isList := a.([]interface{}); isList && ok && replaceLists && len(list) > 0 {
+			if _, objects := list[0].(map[string]interface{}); objects {
+				a = append(append([]interface{}{}, list...), map[string]interface{}{"$patch": "replace"})
+			}
+		}
+		patch[k] = a
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	return patch
+}
+
+// patchHeader returns the fields kustomize uses to find the object a patch applies to
+func patchHeader(obj map[string]interface{}) map[string]interface{} {
+	meta := map[string]interface{}{}
+	if m := field(obj, "metadata"); m != nil {
+		meta["name"] = m["name"]
+		if ns, ok := m["namespace"]; ok {
+			meta["namespace"] = ns
+		}
+	}
+	return map[string]interface{}{
+		"apiVersion": obj["apiVersion"],
+		"kind":       obj["kind"],
+		"metadata":   meta,
+	}
+}
+
+// removeExport removes what an earlier Kustomize wrote to dir, dir/base and dir/overlays/*. Only the files listed in
+// their kustomization.yaml are removed so anything else kept in dir survives
+func removeExport(dir string) error {
+	dirs := []string{dir, filepath.Join(dir, "base")}
+	overlays, err := ioutil.ReadDir(filepath.Join(dir, "overlays"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, o := range overlays {
+		if o.IsDir() {
+			dirs = append(dirs, filepath.Join(dir, "overlays", o.Name()))
+		}
+	}
+	for _, d := range dirs {
+		if err := removeKustomization(d); err != nil {
+			return err
+		}
+	}
+	// directories left empty go too, os.Remove fails on the others
+	for _, d := range dirs[2:] {
+		os.Remove(d)
+	}
+	os.Remove(filepath.Join(dir, "overlays"))
+	os.Remove(filepath.Join(dir, "base"))
+	return nil
+}
+
+func removeKustomization(dir string) error {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	k := kustomization{}
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		return fmt.Errorf("failed to read the earlier export in %s: %s", dir, err.Error())
+	}
+	for _, file := range append(k.Resources, k.PatchesStrategicMerge...) {
+		if file != filepath.Base(file) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, file)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Remove(filepath.Join(dir, "kustomization.yaml"))
+}
+
+func writeKustomization(dir string, k kustomization) error {
+	k.APIVersion = "kustomize.config.k8s.io/v1beta1"
+	k.Kind = "Kustomization"
+	return writeYAML(dir, "kustomization.yaml", k)
+}
+
+func writeYAML(dir, file string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, file), data, 0644)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package generate
+
+import (
+	"github.com/maleck13/templator/model"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+	"k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// buildAutoscalers returns a HorizontalPodAutoscaler for each DeploymentConfig or Deployment generated from dc, so
+// the per node configs of #PerNodeConfig are each scaled on their own. Other controllers cannot be autoscaled
+func buildAutoscalers(dc *model.OSTDeploymentConfig, workloads []runtime.Object, result *Result) []runtime.Object {
+	a := dc.Spec.Autoscaler
+	if nil == a {
+		return nil
+	}
+	switch dc.Spec.ReplicaStrategy {
+	case model.ReplicationStrategy_EqualToNodes:
+		result.warn("deployment %s has replicas %s but its autoscaler sets the replicas between %d and %d whatever the number of nodes", dc.Name, model.ReplicationStrategy_EqualToNodes, a.MinReplicas, a.MaxReplicas)
+	case model.ReplicationStrategy_Single:
+		result.warn("deployment %s has replicas %s but its autoscaler sets the replicas between %d and %d", dc.Name, model.ReplicationStrategy_Single, a.MinReplicas, a.MaxReplicas)
+	}
+	if dc.Spec.Template != nil {
+		for _, c := range dc.Spec.Template.Spec.Containers {
+			if _, ok := c.Resources.Requests[k8.ResourceCPU]; !ok {
+				result.warn("container %s of deployment %s has no cpu request so its autoscaler cannot measure its cpu use", c.Name, dc.Name)
+			}
+		}
+	}
+	var autoscalers []runtime.Object
+	for _, obj := range workloads {
+		switch o := obj.(type) {
+		case *model.DeploymentConfig:
+			autoscalers = append(autoscalers, autoscaler(a, o.ObjectMeta, "DeploymentConfig", "v1"))
+		case *v1beta1.Deployment:
+			autoscalers = append(autoscalers, autoscaler(a, o.ObjectMeta, "Deployment", "extensions/v1beta1"))
+		default:
+			result.warn("deployment %s is a %s which cannot be autoscaled so no autoscaler was generated for it", dc.Name, ObjectKind(obj))
+		}
+	}
+	return autoscalers
+}
+
+// autoscaler is named and labelled after the config it scales
+func autoscaler(a *model.Autoscaler, target k8.ObjectMeta, kind, apiVersion string) *autoscaling.HorizontalPodAutoscaler {
+	hpa := &autoscaling.HorizontalPodAutoscaler{}
+	hpa.Kind = "HorizontalPodAutoscaler"
+	hpa.APIVersion = "autoscaling/v1"
+	hpa.Name = target.Name
+	hpa.Labels = mergeLabels(nil, target.Labels)
+	hpa.Spec.ScaleTargetRef = autoscaling.CrossVersionObjectReference{Kind: kind, Name: target.Name, APIVersion: apiVersion}
+	minReplicas, cpu := a.MinReplicas, a.TargetCPUUtilizationPercentage
+	hpa.Spec.MinReplicas = &minReplicas
+	hpa.Spec.MaxReplicas = a.MaxReplicas
+	hpa.Spec.TargetCPUUtilizationPercentage = &cpu
+	return hpa
+}
@@ -0,0 +1,93 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/maleck13/templator/model"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+func buildDeploymentConfigs(dc *model.OSTDeploymentConfig, opts Options, result *Result) ([]runtime.Object, error) {
+	builtConfigs := make([]runtime.Object, 0)
+	prepareDeploymentConfig(dc, opts, result)
+	if dc.Spec.DeploymentStrategy != model.DeploymentStrategy_PerNodeConfig {
+		return append(builtConfigs, dc.DeploymentConfig()), nil
+	}
+
+	if opts.Nodes == 0 {
+		result.warn("deployment %s is %s but nodes is 0 so no deployment configs were generated for it", dc.Name, model.DeploymentStrategy_PerNodeConfig)
+	}
+	for i := 0; i < opts.Nodes; i++ {
+		cloneDC, err := perNodeClone(dc, i, opts)
+		if err != nil {
+			return nil, err
+		}
+		builtConfigs = append(builtConfigs, cloneDC.DeploymentConfig())
+	}
+	return builtConfigs, nil
+}
+
+// prepareDeploymentConfig removes what opts leave out of the generation and sets the replicas from the replica strategy
+func prepareDeploymentConfig(dc *model.OSTDeploymentConfig, opts Options, result *Result) {
+	if dc.Spec.Template != nil {
+		if !opts.Storage {
+			//remove volumes
+			dc.Spec.Template.Spec.Volumes = nil
+			for i := 0; i < len(dc.Spec.Template.Spec.Containers); i++ {
+				dc.Spec.Template.Spec.Containers[i].VolumeMounts = nil
+			}
+		}
+		if !opts.NodeSelector {
+			//remove nodeSelector
+			dc.Spec.Template.Spec.NodeSelector = nil
+		}
+	}
+	if dc.Spec.ReplicaStrategy == model.ReplicationStrategy_EqualToNodes {
+		if opts.Nodes == 0 {
+			result.warn("deployment %s has replicas %s but nodes is 0 so it will not run any pods", dc.Name, model.ReplicationStrategy_EqualToNodes)
+		}
+		dc.Spec.Replicas = opts.Nodes
+	} else if dc.Spec.ReplicaStrategy == model.ReplicationStrategy_Single {
+		dc.Spec.Replicas = 1
+	}
+}
+
+// perNodeClone returns the copy of dc for the node, named after it and with claims of its own
+func perNodeClone(dc *model.OSTDeploymentConfig, node int, opts Options) (*model.OSTDeploymentConfig, error) {
+	cloneDC, err := cloneDeploymentConfig(dc)
+	if err != nil {
+		return nil, err
+	}
+	cloneDC.ObjectMeta.Name = perNodeName(dc.ObjectMeta.Name, node)
+	if opts.Storage && cloneDC.Spec.Template != nil {
+		//each node gets its own claims
+		for k := 0; k < len(cloneDC.Spec.Template.Spec.Volumes); k++ {
+			if claim := cloneDC.Spec.Template.Spec.Volumes[k].PersistentVolumeClaim; claim != nil {
+				claim.ClaimName = perNodeName(claim.ClaimName, node)
+			}
+		}
+	}
+	return cloneDC, nil
+}
+
+// perNodeName formats a name containing %d with the node index, otherwise the index is appended
+func perNodeName(name string, node int) string {
+	if strings.Contains(name, "%d") {
+		return fmt.Sprintf(name, node)
+	}
+	return fmt.Sprintf("%s-%d", name, node)
+}
+
+func cloneDeploymentConfig(dc *model.OSTDeploymentConfig) (*model.OSTDeploymentConfig, error) {
+	data, err := json.Marshal(dc)
+	if err != nil {
+		return nil, err
+	}
+	cloneDC := &model.OSTDeploymentConfig{}
+	if err := json.Unmarshal(data, cloneDC); err != nil {
+		return nil, err
+	}
+	return cloneDC, nil
+}
@@ -0,0 +1,158 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type ChangeType string
+
+const (
+	ObjectAdded    ChangeType = "+"
+	ObjectRemoved  ChangeType = "-"
+	ObjectModified ChangeType = "~"
+)
+
+// ObjectChange describes how a single object differs between two generated templates
+type ObjectChange struct {
+	Type ChangeType
+	Kind string
+	Name string
+	// Fields are the paths of the fields that differ when the object was modified
+	Fields []string
+}
+
+func (oc ObjectChange) String() string {
+	s := fmt.Sprintf("%s %s/%s", oc.Type, oc.Kind, oc.Name)
+	for _, f := range oc.Fields {
+		s += "\n    " + f
+	}
+	return s
+}
+
+// DiffTemplates compares two generated templates in their json form object by object. Objects are matched on
+// kind and name and the template's own fields are compared as a Template object
+func DiffTemplates(before, after []byte) ([]ObjectChange, error) {
+	beforeObjects, err := templateObjects(before)
+	if err != nil {
+		return nil, err
+	}
+	afterObjects, err := templateObjects(after)
+	if err != nil {
+		return nil, err
+	}
+	return diffObjects(beforeObjects, afterObjects), nil
+}
+
+type objectKey struct {
+	kind string
+	name string
+}
+
+func templateObjects(data []byte) (map[objectKey]interface{}, error) {
+	objects := make(map[objectKey]interface{})
+	if len(data) == 0 {
+		return objects, nil
+	}
+	var template map[string]interface{}
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to decode template %s", err.Error())
+	}
+	items, _ := template["objects"].([]interface{})
+	delete(template, "objects")
+	objects[objectKey{kind: "Template", name: genericName(template)}] = template
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := obj["kind"].(string)
+		objects[objectKey{kind: kind, name: genericName(obj)}] = obj
+	}
+	return objects, nil
+}
+
+func genericName(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+func diffObjects(before, after map[objectKey]interface{}) []ObjectChange {
+	var changes []ObjectChange
+	for key, b := range before {
+		a, ok := after[key]
+		if !ok {
+			changes = append(changes, ObjectChange{Type: ObjectRemoved, Kind: key.kind, Name: key.name})
+			continue
+		}
+		if fields := diffFields("", b, a); len(fields) > 0 {
+			changes = append(changes, ObjectChange{Type: ObjectModified, Kind: key.kind, Name: key.name, Fields: fields})
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, ObjectChange{Type: ObjectAdded, Kind: key.kind, Name: key.name})
+		}
+	}
+	sort.Sort(changeSorter(changes))
+	return changes
+}
+
+// diffFields returns the paths below path where before and after differ
+func diffFields(path string, before, after interface{}) []string {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		var fields []string
+		for k, v := range beforeMap {
+			fields = append(fields, diffFields(joinPath(path, k), v, afterMap[k])...)
+		}
+		for k, v := range afterMap {
+			if _, ok := beforeMap[k]; !ok {
+				fields = append(fields, diffFields(joinPath(path, k), nil, v)...)
+			}
+		}
+		sort.Strings(fields)
+		return fields
+	}
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		var fields []string
+		for i := range beforeList {
+			fields = append(fields, diffFields(fmt.Sprintf("%s[%d]", path, i), beforeList[i], afterList[i])...)
+		}
+		return fields
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []string{path}
+}
+
+func joinPath(path, field string) string {
+	if "" == path {
+		return field
+	}
+	return path + "." + field
+}
+
+type changeSorter []ObjectChange
+
+func (cs changeSorter) Len() int {
+	return len(cs)
+}
+
+func (cs changeSorter) Swap(i, j int) {
+	cs[i], cs[j] = cs[j], cs[i]
+}
+
+func (cs changeSorter) Less(i, j int) bool {
+	if cs[i].Kind != cs[j].Kind {
+		return cs[i].Kind < cs[j].Kind
+	}
+	return cs[i].Name < cs[j].Name
+}
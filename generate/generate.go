@@ -0,0 +1,205 @@
+// Package generate turns a stored ApplicationTemplate into the objects of an OpenShift template for a given
+// set of generation options. It holds no global state so several generations can run at once.
+package generate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/maleck13/templator/model"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+const (
+	// TARGET_OPENSHIFT generates DeploymentConfigs and Routes, it is the default target
+	TARGET_OPENSHIFT = "openshift"
+	// TARGET_KUBERNETES generates Deployments, DaemonSets and StatefulSets and leaves out Routes
+	TARGET_KUBERNETES = "kubernetes"
+	// NODE_LABEL is set to the node index on the selector and pods of each per node Deployment so that they do not
+	// select each other's pods. DeploymentConfigs need none as OpenShift adds their name to the selector
+	NODE_LABEL = "templator/node"
+)
+
+// Options control how an ApplicationTemplate is expanded
+type Options struct {
+	// Nodes is the number of nodes the app is deployed to. It drives #PerNodeConfig and #EqualToNodes
+	Nodes int
+	// Storage keeps the volumes and volume mounts of deployments. Without it they are removed
+	Storage bool
+	// NodeSelector keeps the node selectors of deployments. Without it they are removed
+	NodeSelector bool
+	// Order sorts the generated objects. When nil DefaultOrder is used
+	Order Orderer
+	// Labels are added to every object on top of the template's ObjectLabels. They only reach selectors when their key
+	// is one of the template's SelectorLabels
+	Labels map[string]string
+	// Annotations are added to every object on top of the template's ObjectAnnotations
+	Annotations map[string]string
+	// Namespace is set on every object when not empty
+	Namespace string
+	// Target is the platform the objects are for, TARGET_OPENSHIFT when empty
+	Target string
+}
+
+// ProfileOptions returns the options stored in the named profile of the template
+func ProfileOptions(appTemplate *model.ApplicationTemplate, profile string) (Options, error) {
+	p, ok := appTemplate.Profiles[profile]
+	if !ok {
+		return Options{}, fmt.Errorf("no profile named %s in template %s", profile, appTemplate.Name)
+	}
+	return Options{
+		Nodes:        p.Nodes,
+		Storage:      p.Storage,
+		NodeSelector: p.NodeSelector,
+		Namespace:    p.Namespace,
+	}, nil
+}
+
+// Result is the output of a generation
+type Result struct {
+	// Template is the OpenShift template holding Objects
+	Template *model.Template
+	// Objects are the generated objects
+	Objects []runtime.Object
+	// Warnings describe anything in the ApplicationTemplate that could not be generated as asked
+	Warnings []string
+}
+
+func (r *Result) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Generate expands appTemplate according to opts. appTemplate is not modified
+func Generate(appTemplate *model.ApplicationTemplate, opts Options) (*Result, error) {
+	if nil == appTemplate {
+		return nil, fmt.Errorf("no template to generate")
+	}
+	if opts.Nodes < 0 {
+		return nil, fmt.Errorf("nodes cannot be negative got %d", opts.Nodes)
+	}
+	if "" != opts.Target && TARGET_OPENSHIFT != opts.Target && TARGET_KUBERNETES != opts.Target {
+		return nil, fmt.Errorf("unsupported target %s expected %s or %s", opts.Target, TARGET_OPENSHIFT, TARGET_KUBERNETES)
+	}
+	appTemp, err := appTemplate.Copy()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	osTemplate := &model.Template{}
+	osTemplate.Kind = appTemp.Kind
+	osTemplate.APIVersion = appTemp.APIVersion
+	osTemplate.ObjectMeta = appTemp.ObjectMeta
+	//the store revision is not part of the generated template
+	osTemplate.ObjectMeta.ResourceVersion = ""
+	for _, p := range appTemp.Parameters {
+		osTemplate.Parameters = append(osTemplate.Parameters, *p)
+	}
+
+	//claims that became the volume claim templates of a StatefulSet are created by it rather than generated
+	claimTemplates := make(map[string]bool)
+	for _, k := range sortedKeys(appTemp.DeploymentConfigs) {
+		var preparedConfigs []runtime.Object
+		if TARGET_KUBERNETES == opts.Target {
+			preparedConfigs, err = buildWorkloads(appTemp.DeploymentConfigs[k], appTemp, opts, result, claimTemplates)
+		} else {
+			preparedConfigs, err = buildDeploymentConfigs(appTemp.DeploymentConfigs[k], opts, result)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Objects = append(result.Objects, preparedConfigs...)
+		result.Objects = append(result.Objects, buildAutoscalers(appTemp.DeploymentConfigs[k], preparedConfigs, result)...)
+	}
+
+	for _, k := range sortedKeys(appTemp.Services) {
+		result.Objects = append(result.Objects, appTemp.Services[k])
+	}
+
+	for _, k := range sortedKeys(appTemp.Routes) {
+		route := appTemp.Routes[k]
+		if TARGET_KUBERNETES == opts.Target {
+			result.warn("route %s is not generated as kubernetes has no routes, expose service %s with an ingress instead", route.Name, route.Spec.To.Name)
+			continue
+		}
+		if route.Kind == "" {
+			route.Kind = "Route"
+			route.APIVersion = "v1"
+		}
+		result.Objects = append(result.Objects, route)
+	}
+
+	if opts.Storage {
+		mounts := claimMounts(result.Objects)
+		generated := make(map[string]bool)
+		for _, k := range sortedKeys(appTemp.PersistentVolumes) {
+			if claimTemplates[appTemp.PersistentVolumes[k].Name] {
+				continue
+			}
+			claims, err := buildPersistentVolumeClaims(appTemp.PersistentVolumes[k], opts, mounts)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range claims {
+				generated[ObjectName(c)] = true
+			}
+			result.Objects = append(result.Objects, claims...)
+		}
+		for _, m := range mounts {
+			if !generated[m.claim] {
+				result.warn("%s %s mounts claim %s which is not generated", ObjectKind(m.by), ObjectName(m.by), m.claim)
+			}
+		}
+	}
+
+	labels := mergeLabels(appTemp.ObjectLabels, opts.Labels)
+	selectorLabels := make(map[string]string)
+	for _, k := range appTemp.SelectorLabels {
+		if v, ok := labels[k]; ok {
+			selectorLabels[k] = v
+		}
+	}
+	if err := applyLabels(result, labels, selectorLabels, mergeLabels(appTemp.ObjectAnnotations, opts.Annotations)); err != nil {
+		return nil, err
+	}
+	osTemplate.ObjectLabels = labels
+	if "" != opts.Namespace {
+		if err := setNamespace(result.Objects, opts.Namespace); err != nil {
+			return nil, err
+		}
+		osTemplate.Namespace = opts.Namespace
+	}
+
+	order := opts.Order
+	if nil == order {
+		order = DefaultOrder
+	}
+	order.Order(result.Objects)
+
+	osTemplate.Objects = result.Objects
+	result.Template = osTemplate
+	return result, nil
+}
+
+// sortedKeys returns the keys of one of the template's object maps in order so that generation is repeatable
+func sortedKeys(objects interface{}) []string {
+	keys := reflect.ValueOf(objects).MapKeys()
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func setNamespace(objects []runtime.Object, namespace string) error {
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		accessor.SetNamespace(namespace)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package generate
+
+import (
+	"sort"
+
+	"github.com/maleck13/templator/model"
+	"k8s.io/kubernetes/pkg/api/meta"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// applyLabels adds the template wide labels and annotations to every object and pod template. Only selectorLabels,
+// the labels the template marks as stable, are also added to deployment selectors and to the selectors of services
+// that select a generated deployment so they keep matching the relabelled pods. Other labels stay out of selectors as
+// a changed selector orphans the running pods and some selectors cannot be changed at all. For the same reason a
+// label that is not a selector label never replaces a pod label its controller selects on
+func applyLabels(result *Result, labels, selectorLabels, annotations map[string]string) error {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+	objects := result.Objects
+	labelKeys := make([]string, 0, len(labels))
+	for k := range labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	var podLabels []map[string]string
+	for _, obj := range objects {
+		if template := podTemplate(obj); template != nil {
+			podLabels = append(podLabels, template.Labels)
+		}
+	}
+	//decided before any labels are added so services are matched on their stored selectors
+	relabelServices := make(map[*k8.Service]bool)
+	for _, obj := range objects {
+		if s, ok := obj.(*k8.Service); ok && selectsAny(s.Spec.Selector, podLabels) {
+			relabelServices[s] = true
+		}
+	}
+
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		accessor.SetLabels(mergeLabels(accessor.GetLabels(), labels))
+		accessor.SetAnnotations(mergeLabels(accessor.GetAnnotations(), annotations))
+		if template := podTemplate(obj); template != nil {
+			podLabels := labels
+			for _, k := range labelKeys {
+				v := labels[k]
+				_, stable := selectorLabels[k]
+				if selected, ok := selector(obj)[k]; ok && selected != v && !stable {
+					result.warn("label %s=%s is not set on the pods of %s %s as it selects its pods on %s=%s, make it a selector label to change both", k, v, ObjectKind(obj), accessor.GetName(), k, selected)
+					podLabels = withoutLabel(podLabels, k)
+				}
+			}
+			template.Labels = mergeLabels(template.Labels, podLabels)
+			template.Annotations = mergeLabels(template.Annotations, annotations)
+		}
+		if len(selectorLabels) == 0 {
+			continue
+		}
+		switch o := obj.(type) {
+		case *model.DeploymentConfig:
+			if len(o.Spec.Selector) > 0 {
+				o.Spec.Selector = mergeLabels(o.Spec.Selector, selectorLabels)
+			}
+		case *v1beta1.Deployment:
+			if o.Spec.Selector != nil && len(o.Spec.Selector.MatchLabels) > 0 {
+				o.Spec.Selector.MatchLabels = mergeLabels(o.Spec.Selector.MatchLabels, selectorLabels)
+			}
+		case *v1beta1.DaemonSet:
+			if o.Spec.Selector != nil && len(o.Spec.Selector.MatchLabels) > 0 {
+				o.Spec.Selector.MatchLabels = mergeLabels(o.Spec.Selector.MatchLabels, selectorLabels)
+			}
+		case *model.StatefulSet:
+			if o.Spec.Selector != nil && len(o.Spec.Selector.MatchLabels) > 0 {
+				o.Spec.Selector.MatchLabels = mergeLabels(o.Spec.Selector.MatchLabels, selectorLabels)
+			}
+		case *k8.Service:
+			if relabelServices[o] {
+				o.Spec.Selector = mergeLabels(o.Spec.Selector, selectorLabels)
+			}
+		}
+	}
+	return nil
+}
+
+// podTemplate returns the pod template of a generated controller or nil for other objects
+func podTemplate(obj runtime.Object) *k8.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *model.DeploymentConfig:
+		return o.Spec.Template
+	case *v1beta1.Deployment:
+		return &o.Spec.Template
+	case *v1beta1.DaemonSet:
+		return &o.Spec.Template
+	case *model.StatefulSet:
+		return &o.Spec.Template
+	}
+	return nil
+}
+
+// selector returns the labels a generated controller selects its pods on or nil for other objects
+func selector(obj runtime.Object) map[string]string {
+	switch o := obj.(type) {
+	case *model.DeploymentConfig:
+		return o.Spec.Selector
+	case *v1beta1.Deployment:
+		if o.Spec.Selector != nil {
+			return o.Spec.Selector.MatchLabels
+		}
+	case *v1beta1.DaemonSet:
+		if o.Spec.Selector != nil {
+			return o.Spec.Selector.MatchLabels
+		}
+	case *model.StatefulSet:
+		if o.Spec.Selector != nil {
+			return o.Spec.Selector.MatchLabels
+		}
+	}
+	return nil
+}
+
+// withoutLabel returns a copy of labels without key
+func withoutLabel(labels map[string]string, key string) map[string]string {
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k != key {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+func selectsAny(selector map[string]string, podLabels []map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for _, labels := range podLabels {
+		matches := true
+		for k, v := range selector {
+			if labels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeLabels returns a new map holding existing overridden by extra
+func mergeLabels(existing, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return existing
+	}
+	merged := make(map[string]string, len(existing)+len(extra))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,72 @@
+package generate
+
+import (
+	"sort"
+
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// Orderer sorts the generated objects into the order they are written to the template
+type Orderer interface {
+	Order(objects []runtime.Object)
+}
+
+// KindOrder orders objects by the position of their kind in the list and then by name. Kinds that are not
+// listed come after the listed ones ordered by kind name
+type KindOrder []string
+
+// DefaultOrder creates what others depend on first so the objects can be created in the order they are listed
+var DefaultOrder = KindOrder{"Secret", "ConfigMap", "PersistentVolumeClaim", "Service", "DeploymentConfig", "Deployment", "DaemonSet", "StatefulSet", "HorizontalPodAutoscaler", "Route"}
+
+func (ko KindOrder) Order(objects []runtime.Object) {
+	sort.Stable(kindSorter{order: ko, objects: objects})
+}
+
+func (ko KindOrder) rank(kind string) int {
+	for i, k := range ko {
+		if k == kind {
+			return i
+		}
+	}
+	return len(ko)
+}
+
+type kindSorter struct {
+	order   KindOrder
+	objects []runtime.Object
+}
+
+func (ks kindSorter) Len() int {
+	return len(ks.objects)
+}
+
+func (ks kindSorter) Swap(i, j int) {
+	ks.objects[i], ks.objects[j] = ks.objects[j], ks.objects[i]
+}
+
+func (ks kindSorter) Less(i, j int) bool {
+	kindI, kindJ := ObjectKind(ks.objects[i]), ObjectKind(ks.objects[j])
+	rankI, rankJ := ks.order.rank(kindI), ks.order.rank(kindJ)
+	if rankI != rankJ {
+		return rankI < rankJ
+	}
+	if kindI != kindJ {
+		return kindI < kindJ
+	}
+	return ObjectName(ks.objects[i]) < ObjectName(ks.objects[j])
+}
+
+// ObjectKind returns the kind set on a generated object
+func ObjectKind(obj runtime.Object) string {
+	return obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+// ObjectName returns the metadata name of a generated object
+func ObjectName(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetName()
+}
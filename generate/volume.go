@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"encoding/json"
+	"strings"
+
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// claimMount is a claim mounted by the pod template of a generated object
+type claimMount struct {
+	claim string
+	by    runtime.Object
+}
+
+// claimMounts returns the claims the generated objects mount in the order they are mounted
+func claimMounts(objects []runtime.Object) []claimMount {
+	var mounts []claimMount
+	for _, obj := range objects {
+		template := podTemplate(obj)
+		if nil == template {
+			continue
+		}
+		for _, v := range template.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				mounts = append(mounts, claimMount{claim: v.PersistentVolumeClaim.ClaimName, by: obj})
+			}
+		}
+	}
+	return mounts
+}
+
+// buildPersistentVolumeClaims returns a claim per node for claims named with %d. Other claims are generated under the
+// names they are mounted by, which for per node configs have the node index appended the same way the config names
+// do, and under their own name when nothing mounts them
+func buildPersistentVolumeClaims(pvc *k8.PersistentVolumeClaim, opts Options, mounts []claimMount) ([]runtime.Object, error) {
+	if pvc.Kind == "" {
+		pvc.Kind = "PersistentVolumeClaim"
+		pvc.APIVersion = "v1"
+	}
+	var names []string
+	if strings.Contains(pvc.Name, "%d") {
+		for i := 0; i < opts.Nodes; i++ {
+			names = append(names, perNodeName(pvc.Name, i))
+		}
+	} else {
+		mounted := make(map[string]bool, len(mounts))
+		for _, m := range mounts {
+			mounted[m.claim] = true
+		}
+		if mounted[pvc.Name] {
+			names = append(names, pvc.Name)
+		}
+		for i := 0; i < opts.Nodes; i++ {
+			if name := perNodeName(pvc.Name, i); mounted[name] {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return []runtime.Object{pvc}, nil
+		}
+	}
+	claims := make([]runtime.Object, 0, len(names))
+	for _, name := range names {
+		data, err := json.Marshal(pvc)
+		if err != nil {
+			return nil, err
+		}
+		clone := &k8.PersistentVolumeClaim{}
+		if err := json.Unmarshal(data, clone); err != nil {
+			return nil, err
+		}
+		clone.Name = name
+		claims = append(claims, clone)
+	}
+	return claims, nil
+}
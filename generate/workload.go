@@ -0,0 +1,209 @@
+package generate
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/maleck13/templator/model"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// buildWorkloads returns the kubernetes controllers for a stored deployment config. Its workload kind picks a
+// DaemonSet or a StatefulSet, otherwise it is a Deployment cloned per node for #PerNodeConfig the same way
+// DeploymentConfigs are. Claims used as volume claim templates are added to claimTemplates
+func buildWorkloads(dc *model.OSTDeploymentConfig, appTemp *model.ApplicationTemplate, opts Options, result *Result, claimTemplates map[string]bool) ([]runtime.Object, error) {
+	prepareDeploymentConfig(dc, opts, result)
+	if nil == dc.Spec.Template {
+		result.warn("deployment %s has no pod template so no %s was generated for it", dc.Name, workloadKind(dc))
+		return nil, nil
+	}
+	warnOpenShiftOnly(dc, result)
+	switch dc.Spec.WorkloadKind {
+	case model.WorkloadKind_DaemonSet:
+		return []runtime.Object{daemonSet(dc, result)}, nil
+	case model.WorkloadKind_StatefulSet:
+		return []runtime.Object{statefulSet(dc, appTemp, opts, result, claimTemplates)}, nil
+	}
+	if dc.Spec.DeploymentStrategy != model.DeploymentStrategy_PerNodeConfig {
+		return []runtime.Object{deployment(dc)}, nil
+	}
+	if opts.Nodes == 0 {
+		result.warn("deployment %s is %s but nodes is 0 so no deployments were generated for it", dc.Name, model.DeploymentStrategy_PerNodeConfig)
+	}
+	workloads := make([]runtime.Object, 0, opts.Nodes)
+	for i := 0; i < opts.Nodes; i++ {
+		cloneDC, err := perNodeClone(dc, i, opts)
+		if err != nil {
+			return nil, err
+		}
+		SetNodeLabel(cloneDC, strconv.Itoa(i))
+		workloads = append(workloads, deployment(cloneDC))
+	}
+	return workloads, nil
+}
+
+// SetNodeLabel adds NODE_LABEL with value to the selector and pod labels of a per node clone
+func SetNodeLabel(dc *model.OSTDeploymentConfig, value string) {
+	dc.Spec.Selector = mergeLabels(dc.Spec.Selector, map[string]string{NODE_LABEL: value})
+	if dc.Spec.Template != nil {
+		dc.Spec.Template.Labels = mergeLabels(dc.Spec.Template.Labels, map[string]string{NODE_LABEL: value})
+	}
+}
+
+func workloadKind(dc *model.OSTDeploymentConfig) string {
+	if "" == dc.Spec.WorkloadKind {
+		return "Deployment"
+	}
+	return dc.Spec.WorkloadKind
+}
+
+// warnOpenShiftOnly warns about the parts of a deployment config kubernetes controllers have no equivalent for
+func warnOpenShiftOnly(dc *model.OSTDeploymentConfig, result *Result) {
+	for _, t := range dc.Spec.Triggers {
+		if "ImageChange" == t.Type {
+			result.warn("deployment %s has an image change trigger which kubernetes does not support, the image is not updated when the tag moves", dc.Name)
+			break
+		}
+	}
+	var hooks []*model.LifecycleHook
+	if p := dc.Spec.Strategy.RollingParams; p != nil {
+		hooks = append(hooks, p.Pre, p.Post)
+	}
+	if p := dc.Spec.Strategy.RecreateParams; p != nil {
+		hooks = append(hooks, p.Pre, p.Mid, p.Post)
+	}
+	for _, h := range hooks {
+		if h != nil {
+			result.warn("deployment %s has lifecycle hooks which kubernetes does not support and are not generated", dc.Name)
+			break
+		}
+	}
+	if model.DeploymentStrategyTypeCustom == dc.Spec.Strategy.Type {
+		result.warn("deployment %s has a custom strategy which kubernetes does not support, a rolling update is used", dc.Name)
+	}
+}
+
+func deployment(dc *model.OSTDeploymentConfig) *v1beta1.Deployment {
+	d := &v1beta1.Deployment{}
+	d.Kind = "Deployment"
+	d.APIVersion = "extensions/v1beta1"
+	d.ObjectMeta = dc.ObjectMeta
+	replicas := int32(dc.Spec.Replicas)
+	d.Spec.Replicas = &replicas
+	d.Spec.Selector = &v1beta1.LabelSelector{MatchLabels: dc.Spec.Selector}
+	d.Spec.Template = *dc.Spec.Template
+	switch dc.Spec.Strategy.Type {
+	case model.DeploymentStrategyTypeRecreate:
+		d.Spec.Strategy.Type = v1beta1.RecreateDeploymentStrategyType
+	default:
+		d.Spec.Strategy.Type = v1beta1.RollingUpdateDeploymentStrategyType
+		if p := dc.Spec.Strategy.RollingParams; p != nil && (p.MaxSurge != nil || p.MaxUnavailable != nil) {
+			d.Spec.Strategy.RollingUpdate = &v1beta1.RollingUpdateDeployment{MaxSurge: p.MaxSurge, MaxUnavailable: p.MaxUnavailable}
+		}
+	}
+	return d
+}
+
+// daemonSet runs the pod on every node the node selector allows, so nodes and replicas do not apply
+func daemonSet(dc *model.OSTDeploymentConfig, result *Result) *v1beta1.DaemonSet {
+	ds := &v1beta1.DaemonSet{}
+	ds.Kind = "DaemonSet"
+	ds.APIVersion = "extensions/v1beta1"
+	ds.ObjectMeta = dc.ObjectMeta
+	ds.Name = WorkloadName(dc.Name)
+	ds.Spec.Selector = &v1beta1.LabelSelector{MatchLabels: dc.Spec.Selector}
+	ds.Spec.Template = *dc.Spec.Template
+	if model.ReplicationStrategy_Single == dc.Spec.ReplicaStrategy {
+		result.warn("deployment %s is a DaemonSet which runs a pod on every node so replicas %s does not apply", dc.Name, model.ReplicationStrategy_Single)
+	}
+	for _, v := range ds.Spec.Template.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil && strings.Contains(v.PersistentVolumeClaim.ClaimName, "%d") {
+			result.warn("deployment %s is a DaemonSet whose pods cannot have a claim %s each, use a host path volume or a StatefulSet", dc.Name, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return ds
+}
+
+// statefulSet runs a pod per node for #PerNodeConfig, otherwise the replicas, with stable names. The claims a cloned
+// deployment config would have one of per node, those named with %d and for #PerNodeConfig every claim, become volume
+// claim templates so every pod gets its own
+func statefulSet(dc *model.OSTDeploymentConfig, appTemp *model.ApplicationTemplate, opts Options, result *Result, claimTemplates map[string]bool) *model.StatefulSet {
+	ss := &model.StatefulSet{}
+	ss.Kind = "StatefulSet"
+	ss.APIVersion = "apps/v1beta1"
+	ss.ObjectMeta = dc.ObjectMeta
+	ss.Name = WorkloadName(dc.Name)
+	replicas := int32(dc.Spec.Replicas)
+	perNode := dc.Spec.DeploymentStrategy == model.DeploymentStrategy_PerNodeConfig
+	if perNode {
+		if opts.Nodes == 0 {
+			result.warn("deployment %s is %s but nodes is 0 so its StatefulSet has no pods", dc.Name, model.DeploymentStrategy_PerNodeConfig)
+		}
+		replicas = int32(opts.Nodes)
+	}
+	ss.Spec.Replicas = &replicas
+	ss.Spec.Selector = &unversioned.LabelSelector{MatchLabels: dc.Spec.Selector}
+	ss.Spec.Template = *dc.Spec.Template
+
+	var volumes []k8.Volume
+	for _, v := range ss.Spec.Template.Spec.Volumes {
+		if nil == v.PersistentVolumeClaim || !perNode && !strings.Contains(v.PersistentVolumeClaim.ClaimName, "%d") {
+			volumes = append(volumes, v)
+			continue
+		}
+		claimName := v.PersistentVolumeClaim.ClaimName
+		var stored *k8.PersistentVolumeClaim
+		for _, pvc := range appTemp.PersistentVolumes {
+			if pvc.Name == claimName {
+				stored = pvc
+			}
+		}
+		if nil == stored {
+			result.warn("deployment %s mounts claim %s which is not in the template so volume %s is left out of its StatefulSet", dc.Name, claimName, v.Name)
+			continue
+		}
+		//the StatefulSet names the claims <template>-<set>-<ordinal> and mounts them by the template name
+		claimTemplate := k8.PersistentVolumeClaim{
+			ObjectMeta: k8.ObjectMeta{Name: v.Name, Labels: stored.Labels, Annotations: stored.Annotations},
+			Spec:       stored.Spec,
+		}
+		ss.Spec.VolumeClaimTemplates = append(ss.Spec.VolumeClaimTemplates, claimTemplate)
+		claimTemplates[claimName] = true
+	}
+	ss.Spec.Template.Spec.Volumes = volumes
+	ss.Spec.ServiceName = governingService(dc, ss.Name, appTemp, result)
+	return ss
+}
+
+// governingService returns the headless service selecting the pods of dc, which the StatefulSet needs for the pods
+// to get dns names
+func governingService(dc *model.OSTDeploymentConfig, name string, appTemp *model.ApplicationTemplate, result *Result) string {
+	selecting := ""
+	for _, k := range sortedKeys(appTemp.Services) {
+		s := appTemp.Services[k]
+		if !dc.SelectedBy(s.Spec.Selector) {
+			continue
+		}
+		if k8.ClusterIPNone == s.Spec.ClusterIP {
+			return s.Name
+		}
+		if "" == selecting {
+			selecting = s.Name
+		}
+	}
+	if "" != selecting {
+		result.warn("service %s governs StatefulSet %s but is not headless so its pods get no dns names of their own", selecting, name)
+		return selecting
+	}
+	result.warn("no service selects StatefulSet %s, create a headless service named %s for its pods to get dns names", name, name)
+	return name
+}
+
+// WorkloadName is the name of the DaemonSet or StatefulSet that replaces the per node clones, the name without its %d
+func WorkloadName(name string) string {
+	name = strings.Replace(name, "-%d", "", -1)
+	return strings.Replace(name, "%d", "", -1)
+}
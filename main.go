@@ -1,37 +1,90 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
-	"os"
-
 	"fmt"
-	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/urfave/cli"
+	"github.com/maleck13/templator/catalog"
+	"github.com/maleck13/templator/cmd"
+	catalogcmd "github.com/maleck13/templator/cmd/catalog"
+	"github.com/maleck13/templator/cmd/clone"
+	"github.com/maleck13/templator/cmd/completion"
 	"github.com/maleck13/templator/cmd/create"
 	"github.com/maleck13/templator/cmd/del"
+	"github.com/maleck13/templator/cmd/edit"
+	"github.com/maleck13/templator/cmd/graph"
+	"github.com/maleck13/templator/cmd/label"
+	"github.com/maleck13/templator/cmd/profile"
 	"github.com/maleck13/templator/cmd/read"
+	"github.com/maleck13/templator/cmd/resources"
+	"github.com/maleck13/templator/cmd/rotate"
+	"github.com/maleck13/templator/cmd/serve"
+	"github.com/maleck13/templator/cmd/set"
+	"github.com/maleck13/templator/cmd/verify"
+	"github.com/maleck13/templator/export"
+	"github.com/maleck13/templator/generate"
 	"github.com/maleck13/templator/model"
-	"k8s.io/kubernetes/pkg/runtime"
-)
-
-var (
-	nodes        int
-	storage      bool
-	nodeSelector bool
-)
-
-const (
-	TEMPLATE_DB = "./.templates.json"
+	"github.com/maleck13/templator/secret"
+	"github.com/maleck13/templator/service"
+	"github.com/maleck13/templator/watch"
+	"github.com/urfave/cli"
 )
 
 func main() {
 	app := cli.NewApp()
+	app.EnableBashCompletion = true
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:        "store",
+			Value:       service.TEMPLATES_FILE_LOC,
+			Usage:       "--store=<file> the template store to use",
+			EnvVar:      "TEMPLATOR_STORE",
+			Destination: &service.StoreLocation,
+		},
+		cli.StringFlag{
+			Name:        "key-file",
+			Value:       secret.DefaultKeyLocation(),
+			Usage:       "--key-file=<file> the key sensitive values are encrypted with",
+			EnvVar:      "TEMPLATOR_KEY_FILE",
+			Destination: &secret.KeyLocation,
+		},
+		cli.StringSliceFlag{
+			Name:   "catalog",
+			Usage:  "--catalog=<dir> a directory of json starter templates, can be repeated. Defaults to " + catalog.DefaultDir(),
+			EnvVar: "TEMPLATOR_CATALOG",
+		},
+	}
 	app.Commands = []cli.Command{
 		create.CreateCmd(),
 		del.DeleteCmd(),
 		read.ReadCmd(),
 		generateCmd(),
+		clone.CopyCmd(),
+		clone.RenameCmd(),
+		verify.VerifyCmd(),
+		label.LabelCmd(),
+		label.AnnotateCmd(),
+		profile.ProfileCmd(),
+		graph.GraphCmd(),
+		resources.ResourcesCmd(),
+		rotate.RotateKeyCmd(),
+		completion.CompletionCmd(),
+		edit.EditCmd(),
+		set.SetCmd(),
+		serve.ServeCmd(),
+		catalogcmd.CatalogCmd(),
+	}
+	app.Before = func(context *cli.Context) error {
+		if dirs := context.GlobalStringSlice("catalog"); len(dirs) > 0 {
+			catalog.Dirs = dirs
+		}
+		return nil
 	}
 
 	app.Run(os.Args)
@@ -39,131 +92,300 @@ func main() {
 
 func generateCmd() cli.Command {
 	return cli.Command{
-		Name:      "generate",
-		ArgsUsage: "<template>",
-		Action:    generateAction,
-		Usage:     "generate <template> --nodes=3 --storage --nodeSelector",
+		Name:         "generate",
+		ArgsUsage:    "<template>",
+		BashComplete: cmd.CompleteArgs(cmd.TemplateNames),
+		Action:       generateAction,
+		Usage:        "generate <template> --nodes=3 --storage --nodeSelector or generate <template> --profile=prod",
 		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "profile",
+				Usage: "--profile=prod uses the options of a stored profile, flags given as well override it",
+			},
+			cli.StringFlag{
+				Name:  "namespace",
+				Usage: "--namespace=myapp sets the namespace of every object",
+			},
 			cli.IntFlag{
-				Name:        "nodes",
-				Destination: &nodes,
+				Name: "nodes",
 			},
 			cli.BoolFlag{
-				Name:        "storage",
-				Destination: &storage,
+				Name: "storage",
 			},
 			cli.BoolFlag{
-				Name:        "nodeSelector",
-				Destination: &nodeSelector,
+				Name: "nodeSelector",
+			},
+			cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "--label=team=payments adds a label to every object, can be repeated",
+			},
+			cli.StringSliceFlag{
+				Name:  "annotation",
+				Usage: "--annotation=owner=me adds an annotation to every object, can be repeated",
+			},
+			cli.StringFlag{
+				Name:  "order",
+				Usage: "--order=Secret,ConfigMap,PersistentVolumeClaim,Service,DeploymentConfig,Route the kind order objects are written in",
+			},
+			cli.StringFlag{
+				Name:  "target",
+				Value: generate.TARGET_OPENSHIFT,
+				Usage: "--target=openshift|kubernetes the platform to generate for, kubernetes gets Deployments, DaemonSets and StatefulSets in a List",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Value: "template",
+				Usage: "--format=template|helm|kustomize|compose the format to generate, helm and kustomize write to the --out directory",
+			},
+			cli.StringSliceFlag{
+				Name:  "overlay",
+				Usage: "--overlay=prod --overlay=5 adds a kustomize overlay for a stored profile or a number of nodes, can be repeated",
+			},
+			cli.StringFlag{
+				Name:  "out",
+				Usage: "--out=<file|dir> where to write the output, the template is printed when not set",
+			},
+			cli.BoolFlag{
+				Name:  "watch",
+				Usage: "--watch keeps running and writes to --out again whenever the template store or key file changes",
+			},
+			cli.DurationFlag{
+				Name:  "interval",
+				Value: watch.DEFAULT_INTERVAL,
+				Usage: "--interval=2s how often --watch checks for changes",
 			},
 		},
 	}
 }
 
-type writeJson struct {
-	Encoder *json.Encoder
-	writer  io.Writer
-}
-
-func NewWriteJson() *writeJson {
-	return &writeJson{
-		json.NewEncoder(os.Stdout),
-		os.Stdout,
-	}
-}
-
-func (wj *writeJson) Write(p []byte) (n int, err error) {
-	//todo find a way to format
-	return wj.writer.Write(p)
-}
-
 func generateAction(context *cli.Context) error {
 	if len(context.Args()) != 1 {
 		return cli.NewExitError(context.Command.Usage, 1)
 	}
 	var templateName = context.Args()[0]
-	file, err := os.Open(TEMPLATE_DB)
+	if err := checkOut(context.String("format"), context.String("out")); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if context.Bool("watch") {
+		if "" == context.String("out") {
+			return cli.NewExitError("--watch needs an --out file or directory to write to", 1)
+		}
+		return watchGenerate(context, templateName)
+	}
+	appTemplate, opts, err := loadGeneration(context, templateName)
 	if err != nil {
-		return cli.NewExitError("failed to open file "+err.Error(), 1)
+		return cli.NewExitError(err.Error(), 1)
 	}
-	defer file.Close()
-	var modelTemp map[string]*model.ApplicationTemplate
-
-	decoder := json.NewDecoder(file)
+	if err := writeGeneration(context, appTemplate, opts); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return nil
+}
 
-	if err := decoder.Decode(&modelTemp); err != nil {
-		return cli.NewExitError("failed to decode json "+err.Error(), 1)
+// loadGeneration reads and decrypts the named template and works out the options to generate it with from its
+// profile and the command line
+func loadGeneration(context *cli.Context, templateName string) (*model.ApplicationTemplate, generate.Options, error) {
+	opts := generate.Options{}
+	appTemplate, err := service.NewTemplateService("local").GetTemplate(templateName)
+	if err != nil {
+		return nil, opts, fmt.Errorf("failed to load template %s", err.Error())
+	}
+	if nil == appTemplate {
+		return nil, opts, fmt.Errorf("no template named %s", templateName)
+	}
+	if err := secret.DecryptTemplate(appTemplate); err != nil {
+		return nil, opts, err
 	}
 
-	appTemplate := modelTemp[templateName]
-	osTemplate := &model.Template{}
-	osTemplate.Kind = appTemplate.Kind
-	osTemplate.APIVersion = appTemplate.APIVersion
-	osTemplate.ObjectMeta = appTemplate.ObjectMeta
+	if profile := context.String("profile"); "" != profile {
+		if opts, err = generate.ProfileOptions(appTemplate, profile); err != nil {
+			return nil, opts, err
+		}
+	}
+	if context.IsSet("nodes") {
+		opts.Nodes = context.Int("nodes")
+	}
+	if context.IsSet("storage") {
+		opts.Storage = context.Bool("storage")
+	}
+	if context.IsSet("nodeSelector") {
+		opts.NodeSelector = context.Bool("nodeSelector")
+	}
+	if context.IsSet("namespace") {
+		opts.Namespace = context.String("namespace")
+	}
+	if opts.Labels, err = cmd.ParseKeyValues(context.StringSlice("label")); err != nil {
+		return nil, opts, err
+	}
+	if opts.Annotations, err = cmd.ParseKeyValues(context.StringSlice("annotation")); err != nil {
+		return nil, opts, err
+	}
+	if order := context.String("order"); "" != order {
+		opts.Order = generate.KindOrder(strings.Split(order, ","))
+	}
+	opts.Target = context.String("target")
+	return appTemplate, opts, nil
+}
 
-	for _, v := range appTemplate.DeploymentConfigs {
-		preparedConfig := buildDeploymentConfigs(v)
-		osTemplate.Objects = append(osTemplate.Objects, preparedConfig...)
+// writeGeneration generates appTemplate in the --format asked for and writes it to --out
+func writeGeneration(context *cli.Context, appTemplate *model.ApplicationTemplate, opts generate.Options) error {
+	out := context.String("out")
+	switch context.String("format") {
+	case "template":
+		if generate.TARGET_KUBERNETES == opts.Target {
+			data, warnings, err := export.Kubernetes(appTemplate, opts)
+			if err != nil {
+				return err
+			}
+			printWarnings(warnings)
+			return writeOutput(out, data)
+		}
+	case "helm":
+		if "" == out {
+			return fmt.Errorf("--format=helm needs an --out directory to write the chart to")
+		}
+		warnings, err := export.HelmChart(appTemplate, opts, out)
+		if err != nil {
+			return err
+		}
+		printWarnings(warnings)
+		return nil
+	case "kustomize":
+		if "" == out {
+			return fmt.Errorf("--format=kustomize needs an --out directory to write to")
+		}
+		overlays, err := kustomizeOverlays(appTemplate, opts, context.StringSlice("overlay"), context.IsSet("namespace"))
+		if err != nil {
+			return err
+		}
+		warnings, err := export.Kustomize(appTemplate, opts, overlays, out)
+		if err != nil {
+			return err
+		}
+		printWarnings(warnings)
+		return nil
+	case "compose":
+		data, warnings, err := export.Compose(appTemplate, opts)
+		if err != nil {
+			return err
+		}
+		printWarnings(warnings)
+		return writeOutput(out, data)
+	default:
+		return fmt.Errorf("unsupported format %s expected template|helm|kustomize|compose", context.String("format"))
 	}
 
-	for _, v := range appTemplate.Services {
-		osTemplate.Objects = append(osTemplate.Objects, v)
+	result, err := generate.Generate(appTemplate, opts)
+	if err != nil {
+		return err
 	}
+	printWarnings(result.Warnings)
 
-	data, err := json.MarshalIndent(osTemplate, "", " ")
+	data, err := json.MarshalIndent(result.Template, "", " ")
 	if err != nil {
-		return cli.NewExitError(err.Error(), 1)
+		return err
 	}
-	fmt.Println(string(data))
+	return writeOutput(out, append(data, '\n'))
+}
 
-	return nil
+// watchGenerate writes the generation to --out and writes it again whenever the store or the key file changes,
+// printing which objects changed. Those are the only files a generation reads as templates hold no references to
+// other files. Failures are printed and watching carries on so that a half finished change does not stop the watch
+func watchGenerate(context *cli.Context, templateName string) error {
+	var previous []byte
+	files := func() []string {
+		return []string{service.StoreLocation, secret.KeyLocation}
+	}
+	w := watch.New(context.Duration("interval"))
+	fmt.Printf("watching %s for changes to template %s, press ctrl-c to stop\n", service.StoreLocation, templateName)
+	return w.Run(files, func(changed []string) error {
+		stamp := time.Now().Format("15:04:05")
+		appTemplate, opts, err := loadGeneration(context, templateName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s error: %s\n", stamp, err.Error())
+			return nil
+		}
+		result, err := generate.Generate(appTemplate, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s error: %s\n", stamp, err.Error())
+			return nil
+		}
+		generated, err := json.Marshal(result.Template)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		if previous != nil && bytes.Equal(previous, generated) {
+			return nil
+		}
+		if err := writeGeneration(context, appTemplate, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "%s error: %s\n", stamp, err.Error())
+			return nil
+		}
+		if nil == previous {
+			fmt.Printf("%s generated %d objects to %s\n", stamp, len(result.Objects), context.String("out"))
+			previous = generated
+			return nil
+		}
+		changes, err := generate.DiffTemplates(previous, generated)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		previous = generated
+		fmt.Printf("%s regenerated %s after changes to %s\n", stamp, context.String("out"), strings.Join(changed, ", "))
+		for _, c := range changes {
+			fmt.Println("  " + c.String())
+		}
+		return nil
+	})
 }
 
-//func buildTemplate(ostTemplate *ApplicationTemplate)*Template{
-//
-//}
-
-func buildDeploymentConfigs(dc *model.OSTDeploymentConfig) []runtime.Object {
-	builtConfigs := make([]runtime.Object, 0)
-	if !storage {
-		//remove volumes
-		dc.Spec.Template.Spec.Volumes = nil
-		for i := 0; i < len(dc.Spec.Template.Spec.Containers); i++ {
-			dc.Spec.Template.Spec.Containers[i].VolumeMounts = nil
-		}
-	}
-	if !nodeSelector {
-		//remove nodeSelector
-		dc.Spec.Template.Spec.NodeSelector = nil
-	}
-	if dc.Spec.DeploymentStrategy == model.DeploymentStrategy_PerNodeConfig {
-		for i := 0; i < nodes; i++ {
-			//clone the object  it is only a shallow clone
-			var cloneDC *model.OSTDeploymentConfig = &model.OSTDeploymentConfig{}
-			*cloneDC = *dc
-			//need to replace some fields manually added for now but walking the object might be an option
-			cloneDC.ObjectMeta.Name = fmt.Sprintf(cloneDC.ObjectMeta.Name, i)
-			if storage {
-				//inc name of claims
-				for k := 0; k < len(dc.Spec.Template.Spec.Volumes); k++ {
-					claimName := cloneDC.Spec.Template.Spec.Volumes[k].PersistentVolumeClaim.ClaimName
-					cloneDC.Spec.Template.Spec.Volumes[k].PersistentVolumeClaim.ClaimName = fmt.Sprintf(claimName, k)
-				}
-			}
-			if dc.Spec.ReplicaStrategy == model.ReplicationStrategy_EqualToNodes {
-				cloneDC.Spec.Replicas = nodes
+// checkOut fails when --out is a directory but the format writes a single file, before any generation is done
+func checkOut(format, out string) error {
+	if "helm" == format || "kustomize" == format || "" == out {
+		return nil
+	}
+	if info, err := os.Stat(out); err == nil && info.IsDir() {
+		return fmt.Errorf("--out=%s is a directory but --format=%s writes a single file", out, format)
+	}
+	return nil
+}
 
-			}
-			builtConfigs = append(builtConfigs, cloneDC)
+// writeOutput writes data to the out file or stdout when no file is given
+func writeOutput(out string, data []byte) error {
+	if "" == out {
+		os.Stdout.Write(data)
+		return nil
+	}
+	return ioutil.WriteFile(out, data, 0644)
+}
 
+// kustomizeOverlays turns each --overlay into the options of a stored profile or, for a number, the base options with
+// that many nodes. The labels, annotations, order and target given on the command line apply to every overlay, as does
+// the namespace when namespaceSet
+func kustomizeOverlays(appTemplate *model.ApplicationTemplate, base generate.Options, names []string, namespaceSet bool) ([]export.Overlay, error) {
+	var overlays []export.Overlay
+	for _, name := range names {
+		if nodes, err := strconv.Atoi(name); err == nil {
+			opts := base
+			opts.Nodes = nodes
+			overlays = append(overlays, export.Overlay{Name: fmt.Sprintf("nodes-%d", nodes), Options: opts})
+			continue
+		}
+		opts, err := generate.ProfileOptions(appTemplate, name)
+		if err != nil {
+			return nil, err
 		}
-	} else if dc.Spec.DeploymentStrategy == model.DeploymentStrategy_SingleConfig {
-		if dc.Spec.ReplicaStrategy == model.ReplicationStrategy_EqualToNodes {
-			dc.Spec.Replicas = nodes
+		opts.Labels, opts.Annotations, opts.Order, opts.Target = base.Labels, base.Annotations, base.Order, base.Target
+		if namespaceSet {
+			opts.Namespace = base.Namespace
 		}
-		builtConfigs = append(builtConfigs, dc)
-	} else {
-		builtConfigs = append(builtConfigs, dc)
+		overlays = append(overlays, export.Overlay{Name: name, Options: opts})
+	}
+	return overlays, nil
+}
+
+func printWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "warning: "+w)
 	}
-	return builtConfigs
 }
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// kubernetes types that are not part of the vendored kubernetes
+
+// StatefulSet is the apps/v1beta1 StatefulSet. It gives every pod a stable name and its own claims made from the
+// volume claim templates
+type StatefulSet struct {
+	unversioned.TypeMeta `json:",inline"`
+	k8.ObjectMeta        `json:"metadata,omitempty"`
+
+	Spec StatefulSetSpec `json:"spec"`
+}
+
+func (ss *StatefulSet) GetObjectKind() unversioned.ObjectKind {
+	return &ss.TypeMeta
+}
+
+type StatefulSetSpec struct {
+	// Replicas is the number of pods, named <name>-0 to <name>-<replicas-1>
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Selector must match the labels of Template
+	Selector *unversioned.LabelSelector `json:"selector,omitempty"`
+	// Template is the pod every replica is created from
+	Template k8.PodTemplateSpec `json:"template"`
+	// VolumeClaimTemplates are the claims every pod gets its own copy of. A volume mount of Template refers to one
+	// by its name
+	VolumeClaimTemplates []k8.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	// ServiceName is the headless service that gives the pods their dns names
+	ServiceName string `json:"serviceName"`
+}
@@ -1,6 +1,11 @@
 package model
 
 import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
 	"k8s.io/kubernetes/pkg/api/unversioned"
 	k8 "k8s.io/kubernetes/pkg/api/v1"
 )
@@ -10,7 +15,7 @@ func NewApplicationTemplate(name string) *ApplicationTemplate {
 	at.ObjectMeta = k8.ObjectMeta{}
 	at.ObjectMeta.Name = name
 	at.ObjectMeta.Annotations = make(map[string]string)
-	at.ObjectMeta.Annotations["description"] = "a generated template for " + name
+	at.ObjectMeta.Annotations["description"] = defaultDescription(name)
 	at.DeploymentConfigs = make(map[string]*OSTDeploymentConfig)
 	at.Parameters = make([]*Parameter, 0)
 	at.PersistentVolumes = make(map[string]*k8.PersistentVolumeClaim)
@@ -23,6 +28,10 @@ func NewApplicationTemplate(name string) *ApplicationTemplate {
 	return at
 }
 
+func defaultDescription(name string) string {
+	return "a generated template for " + name
+}
+
 type ApplicationTemplate struct {
 	unversioned.TypeMeta `json:",inline"`
 	k8.ObjectMeta        `json:"metadata,omitempty"`
@@ -32,4 +41,155 @@ type ApplicationTemplate struct {
 	Pods                 map[string]*k8.Pod                   `json:"pods"`
 	Routes               map[string]*Route                    `json:"routes"`
 	Parameters           []*Parameter                         `json:"parameters"`
+	// ObjectLabels are added to every generated object and its pod template
+	ObjectLabels map[string]string `json:"objectLabels,omitempty"`
+	// SelectorLabels are the keys of the ObjectLabels that are also added to the selectors that match the pods. Only
+	// labels that never change belong here as a changed selector no longer matches the running pods
+	SelectorLabels []string `json:"selectorLabels,omitempty"`
+	// ObjectAnnotations are added to every generated object and its pod template
+	ObjectAnnotations map[string]string `json:"objectAnnotations,omitempty"`
+	// Profiles are named sets of generation options, one per environment
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// Profile holds the generation options for an environment so they do not have to be given on every generate
+type Profile struct {
+	Nodes        int    `json:"nodes"`
+	Storage      bool   `json:"storage"`
+	NodeSelector bool   `json:"nodeSelector"`
+	Namespace    string `json:"namespace,omitempty"`
+}
+
+// DeploymentDependants returns the names of the services that select only the named deployment and the routes that
+// target those services. Services that also select another deployment are left out as they are still in use
+func (at *ApplicationTemplate) DeploymentDependants(depName string) (services []string, routes []string) {
+	dc, ok := at.DeploymentConfigs[depName]
+	if !ok {
+		return nil, nil
+	}
+	for name, s := range at.Services {
+		if !dc.SelectedBy(s.Spec.Selector) {
+			continue
+		}
+		shared := false
+		for otherName, other := range at.DeploymentConfigs {
+			if otherName != depName && other.SelectedBy(s.Spec.Selector) {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			services = append(services, name)
+		}
+	}
+	sort.Strings(services)
+	for name, r := range at.Routes {
+		if r.Spec.To.Kind != "" && r.Spec.To.Kind != "Service" {
+			continue
+		}
+		for _, s := range services {
+			if at.Services[s].Name == r.Spec.To.Name {
+				routes = append(routes, name)
+				break
+			}
+		}
+	}
+	sort.Strings(routes)
+	return services, routes
+}
+
+// Copy returns a deep copy of the template
+func (at *ApplicationTemplate) Copy() (*ApplicationTemplate, error) {
+	data, err := json.Marshal(at)
+	if err != nil {
+		return nil, err
+	}
+	copied := &ApplicationTemplate{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// Rename changes the template name along with the objects named after it. Any object name, label value, selector,
+// route target or claim name that is the old name or starts with "<old name>-" is rewritten so references stay consistent
+func (at *ApplicationTemplate) Rename(newName string) {
+	oldName := at.Name
+	rename := func(ref string) string {
+		if ref == oldName {
+			return newName
+		}
+		if strings.HasPrefix(ref, oldName+"-") {
+			return newName + strings.TrimPrefix(ref, oldName)
+		}
+		return ref
+	}
+	renameValues := func(values map[string]string) {
+		for k, v := range values {
+			values[k] = rename(v)
+		}
+	}
+
+	at.Name = newName
+	if desc, ok := at.Annotations["description"]; ok {
+		if desc == defaultDescription(oldName) {
+			at.Annotations["description"] = defaultDescription(newName)
+		} else {
+			//only whole words so that renaming app leaves application alone
+			word := regexp.MustCompile(`\b` + regexp.QuoteMeta(oldName) + `\b`)
+			at.Annotations["description"] = word.ReplaceAllLiteralString(desc, newName)
+		}
+	}
+
+	deploymentConfigs := make(map[string]*OSTDeploymentConfig, len(at.DeploymentConfigs))
+	for k, dc := range at.DeploymentConfigs {
+		dc.Name = rename(dc.Name)
+		renameValues(dc.Labels)
+		renameValues(dc.Spec.Selector)
+		if dc.Spec.Template != nil {
+			dc.Spec.Template.Name = rename(dc.Spec.Template.Name)
+			renameValues(dc.Spec.Template.Labels)
+			for i, v := range dc.Spec.Template.Spec.Volumes {
+				if v.PersistentVolumeClaim != nil {
+					dc.Spec.Template.Spec.Volumes[i].PersistentVolumeClaim.ClaimName = rename(v.PersistentVolumeClaim.ClaimName)
+				}
+			}
+		}
+		deploymentConfigs[rename(k)] = dc
+	}
+	at.DeploymentConfigs = deploymentConfigs
+
+	services := make(map[string]*k8.Service, len(at.Services))
+	for k, s := range at.Services {
+		s.Name = rename(s.Name)
+		renameValues(s.Labels)
+		renameValues(s.Spec.Selector)
+		services[rename(k)] = s
+	}
+	at.Services = services
+
+	routes := make(map[string]*Route, len(at.Routes))
+	for k, r := range at.Routes {
+		r.Name = rename(r.Name)
+		renameValues(r.Labels)
+		r.Spec.To.Name = rename(r.Spec.To.Name)
+		routes[rename(k)] = r
+	}
+	at.Routes = routes
+
+	volumes := make(map[string]*k8.PersistentVolumeClaim, len(at.PersistentVolumes))
+	for k, pvc := range at.PersistentVolumes {
+		pvc.Name = rename(pvc.Name)
+		renameValues(pvc.Labels)
+		volumes[rename(k)] = pvc
+	}
+	at.PersistentVolumes = volumes
+
+	pods := make(map[string]*k8.Pod, len(at.Pods))
+	for k, pod := range at.Pods {
+		pod.Name = rename(pod.Name)
+		renameValues(pod.Labels)
+		pods[rename(k)] = pod
+	}
+	at.Pods = pods
 }
@@ -35,6 +35,15 @@ type DeploymentStrategy struct {
 // DeploymentStrategyType refers to a specific DeploymentStrategy implementation.
 type DeploymentStrategyType string
 
+const (
+	// DeploymentStrategyTypeRecreate is a simple strategy suitable as a default.
+	DeploymentStrategyTypeRecreate DeploymentStrategyType = "Recreate"
+	// DeploymentStrategyTypeCustom is a user defined strategy.
+	DeploymentStrategyTypeCustom DeploymentStrategyType = "Custom"
+	// DeploymentStrategyTypeRolling uses the Kubernetes RollingUpdater.
+	DeploymentStrategyTypeRolling DeploymentStrategyType = "Rolling"
+)
+
 // CustomDeploymentStrategyParams are the input to the Custom deployment strategy.
 type CustomDeploymentStrategyParams struct {
 	// Image specifies a Docker image which can carry out a deployment.
@@ -77,6 +86,15 @@ type LifecycleHook struct {
 // LifecycleHookFailurePolicy describes possibles actions to take if a hook fails.
 type LifecycleHookFailurePolicy string
 
+const (
+	// LifecycleHookFailurePolicyRetry means retry the hook until it succeeds.
+	LifecycleHookFailurePolicyRetry LifecycleHookFailurePolicy = "Retry"
+	// LifecycleHookFailurePolicyAbort means abort the deployment (if possible).
+	LifecycleHookFailurePolicyAbort LifecycleHookFailurePolicy = "Abort"
+	// LifecycleHookFailurePolicyIgnore means ignore failure and continue the deployment.
+	LifecycleHookFailurePolicyIgnore LifecycleHookFailurePolicy = "Ignore"
+)
+
 // ExecNewPodHook is a hook implementation which runs a command in a new pod
 // based on the specified container which is assumed to be part of the
 // deployment template.
@@ -171,6 +189,10 @@ type DeploymentConfig struct {
 	Status DeploymentConfigStatus `json:"status"`
 }
 
+func (dc *DeploymentConfig) GetObjectKind() unversioned.ObjectKind {
+	return &dc.TypeMeta
+}
+
 // DeploymentConfigSpec represents the desired state of the deployment.
 type DeploymentConfigSpec struct {
 	// Strategy describes how a deployment is executed.
@@ -343,30 +365,30 @@ type DeploymentLogOptions struct {
 type Parameter struct {
 	// Required: Parameter name must be set and it can be referenced in Template
 	// Items using ${PARAMETER_NAME}
-	Name string
+	Name string `json:"name"`
 
 	// Optional: The name that will show in UI instead of parameter 'Name'
-	DisplayName string
+	DisplayName string `json:"displayName,omitempty"`
 
 	// Optional: Parameter can have description
-	Description string
+	Description string `json:"description,omitempty"`
 
 	// Optional: Value holds the Parameter data. If specified, the generator
 	// will be ignored. The value replaces all occurrences of the Parameter
 	// ${Name} expression during the Template to Config transformation.
-	Value string
+	Value string `json:"value,omitempty"`
 
 	// Optional: Generate specifies the generator to be used to generate
 	// random string from an input value specified by From field. The result
 	// string is stored into Value field. If empty, no generator is being
 	// used, leaving the result Value untouched.
-	Generate string
+	Generate string `json:"generate,omitempty"`
 
 	// Optional: From is an input value for the generator.
-	From string
+	From string `json:"from,omitempty"`
 
 	// Optional: Indicates the parameter must have a value.  Defaults to false.
-	Required bool
+	Required bool `json:"required,omitempty"`
 }
 
 // Route encapsulates the inputs needed to connect an alias to endpoints.
@@ -12,6 +12,8 @@ const (
 	ReplicationStrategy_Single       = "#Single"       //if it is single then it will always set replicas to 1
 	DeploymentStrategy_SingleConfig  = "#SingleConfig"
 	DeploymentStrategy_PerNodeConfig = "#PerNodeConfig" //dynamically generate a deployment config per node
+	WorkloadKind_DaemonSet           = "DaemonSet"      //on kubernetes run a pod on every node with a DaemonSet
+	WorkloadKind_StatefulSet         = "StatefulSet"    //on kubernetes run pods with stable names and a claim each with a StatefulSet
 )
 
 // DeploymentConfig represents a configuration for a single deployment (represented as a
@@ -61,10 +63,45 @@ func (osd *OSTDeploymentConfig) GetObjectKind() unversioned.ObjectKind {
 	return &osd.TypeMeta
 }
 
+// DeploymentConfig returns the openshift object without the templator strategies so it can be written into a template
+func (osd *OSTDeploymentConfig) DeploymentConfig() *DeploymentConfig {
+	return &DeploymentConfig{
+		TypeMeta:   osd.TypeMeta,
+		ObjectMeta: osd.ObjectMeta,
+		Spec:       osd.Spec.DeploymentConfigSpec,
+		Status:     osd.Status,
+	}
+}
+
+// SelectedBy returns true if the selector matches the labels of the pods this deployment creates. An empty selector matches nothing
+func (osd *OSTDeploymentConfig) SelectedBy(selector map[string]string) bool {
+	if len(selector) == 0 || osd.Spec.Template == nil {
+		return false
+	}
+	for k, v := range selector {
+		if osd.Spec.Template.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 type OSTDeploymentConfigSpec struct {
 	DeploymentConfigSpec
 	// used to indicate how to dynamically set the number of replicas based on the number of nodes
-	ReplicaStrategy string `json:"-"`
+	ReplicaStrategy string `json:"replicaStrategy,omitempty"`
 	// used to indicate how to dynamically build the number of DeploymentConfigs required based on the number of nodes
-	DeploymentStrategy string `json:"-"`
+	DeploymentStrategy string `json:"deploymentStrategy,omitempty"`
+	// the controller generated for the kubernetes target. Empty is a Deployment, cloned per node for #PerNodeConfig
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	// scales the generated configs on cpu use. Each per node config gets an autoscaler of its own
+	Autoscaler *Autoscaler `json:"autoscaler,omitempty"`
+}
+
+// Autoscaler is generated as a HorizontalPodAutoscaler targeting the DeploymentConfig or Deployment
+type Autoscaler struct {
+	MinReplicas int32 `json:"minReplicas"`
+	MaxReplicas int32 `json:"maxReplicas"`
+	// the average cpu use across the pods as a percentage of their cpu request
+	TargetCPUUtilizationPercentage int32 `json:"targetCPUUtilizationPercentage"`
 }
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+const (
+	// ServiceType_Headless is a ClusterIP service without a cluster ip, used for per pod dns
+	ServiceType_Headless = "headless"
+)
+
+func NewService(name string, selector map[string]string) *k8.Service {
+	serviceTemp := &k8.Service{}
+	serviceTemp.APIVersion = "v1"
+	serviceTemp.Kind = "Service"
+	serviceTemp.ObjectMeta.Name = name
+	serviceTemp.Spec.Selector = make(map[string]string)
+	for k, v := range selector {
+		serviceTemp.Spec.Selector[k] = v
+	}
+	serviceTemp.Spec.Ports = make([]k8.ServicePort, 0)
+	return serviceTemp
+}
+
+// SetServiceType sets the type of the service. Accepts ClusterIP, NodePort, LoadBalancer or headless in any case
+func SetServiceType(s *k8.Service, serviceType string) error {
+	switch strings.ToLower(serviceType) {
+	case "", "clusterip":
+		s.Spec.Type = k8.ServiceTypeClusterIP
+	case "nodeport":
+		s.Spec.Type = k8.ServiceTypeNodePort
+	case "loadbalancer":
+		s.Spec.Type = k8.ServiceTypeLoadBalancer
+	case ServiceType_Headless:
+		s.Spec.Type = k8.ServiceTypeClusterIP
+		s.Spec.ClusterIP = k8.ClusterIPNone
+	default:
+		return fmt.Errorf("unsupported service type %s expected ClusterIP, NodePort, LoadBalancer or headless", serviceType)
+	}
+	return nil
+}
+
+// PortGroup returns the group of a named container port, the part of the name before the first "-". Unnamed
+// ports and names without a "-" are in the default group ""
+func PortGroup(portName string) string {
+	if i := strings.Index(portName, "-"); i > 0 {
+		return portName[:i]
+	}
+	return ""
+}
+
+// ServicesFromPorts builds a service exposing the container ports of the deployment, selecting its pods by their
+// labels. With perPortGroup a service is built for each port group, named <deployment>-<group>
+func ServicesFromPorts(dc *OSTDeploymentConfig, serviceType string, perPortGroup bool) ([]*k8.Service, error) {
+	if dc.Spec.Template == nil {
+		return nil, fmt.Errorf("deployment %s has no pod template", dc.Name)
+	}
+	services := make(map[string]*k8.Service)
+	seen := make(map[string]bool)
+	for _, c := range dc.Spec.Template.Spec.Containers {
+		for _, p := range c.Ports {
+			protocol := p.Protocol
+			if "" == protocol {
+				protocol = k8.ProtocolTCP
+			}
+			key := fmt.Sprintf("%d/%s", p.ContainerPort, protocol)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			name := dc.Name
+			if group := PortGroup(p.Name); perPortGroup && "" != group {
+				name = dc.Name + "-" + group
+			}
+			serviceTemp, ok := services[name]
+			if !ok {
+				serviceTemp = NewService(name, dc.Spec.Template.Labels)
+				if err := SetServiceType(serviceTemp, serviceType); err != nil {
+					return nil, err
+				}
+				services[name] = serviceTemp
+			}
+			port := k8.ServicePort{
+				Name:       p.Name,
+				Protocol:   protocol,
+				Port:       p.ContainerPort,
+				TargetPort: intstr.FromInt(int(p.ContainerPort)),
+			}
+			if "" != p.Name {
+				port.TargetPort = intstr.FromString(p.Name)
+			} else {
+				port.Name = fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), p.ContainerPort)
+			}
+			serviceTemp.Spec.Ports = append(serviceTemp.Spec.Ports, port)
+		}
+	}
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	built := make([]*k8.Service, 0, len(names))
+	for _, name := range names {
+		built = append(built, services[name])
+	}
+	return built, nil
+}
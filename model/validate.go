@@ -0,0 +1,298 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+// objectName allows the %d a per node name is formatted with
+var objectName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// parameterName is what can be referenced as ${NAME} in a template
+var parameterName = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// FieldError is a single invalid field of an object
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (fe FieldError) Error() string {
+	return fe.Field + ": " + fe.Message
+}
+
+// ValidationError holds every invalid field found in an object
+type ValidationError []FieldError
+
+func (ve ValidationError) Error() string {
+	messages := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		messages = append(messages, fe.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) name(field, name string) {
+	if "" == name {
+		v.add(field, "is required")
+		return
+	}
+	if !objectName.MatchString(strings.Replace(name, "%d", "0", -1)) {
+		v.add(field, "%s must be lower case letters, numbers, '-' and '.' and start and end with a letter or number", name)
+	}
+}
+
+func (v *validator) port(field string, port int32) {
+	if port < 1 || port > 65535 {
+		v.add(field, "%d must be between 1 and 65535", port)
+	}
+}
+
+// ValidateDeploymentConfig checks a stored deployment config can be generated
+func ValidateDeploymentConfig(dc *OSTDeploymentConfig) error {
+	v := &validator{}
+	v.name("metadata.name", dc.Name)
+	switch dc.Spec.ReplicaStrategy {
+	case "", ReplicationStrategy_EqualToNodes, ReplicationStrategy_Single:
+	default:
+		v.add("spec.replicaStrategy", "%s must be %s or %s", dc.Spec.ReplicaStrategy, ReplicationStrategy_EqualToNodes, ReplicationStrategy_Single)
+	}
+	switch dc.Spec.DeploymentStrategy {
+	case "", DeploymentStrategy_SingleConfig, DeploymentStrategy_PerNodeConfig:
+	default:
+		v.add("spec.deploymentStrategy", "%s must be %s or %s", dc.Spec.DeploymentStrategy, DeploymentStrategy_SingleConfig, DeploymentStrategy_PerNodeConfig)
+	}
+	switch dc.Spec.WorkloadKind {
+	case "", WorkloadKind_DaemonSet, WorkloadKind_StatefulSet:
+	default:
+		v.add("spec.workloadKind", "%s must be %s or %s", dc.Spec.WorkloadKind, WorkloadKind_DaemonSet, WorkloadKind_StatefulSet)
+	}
+	if a := dc.Spec.Autoscaler; a != nil {
+		if a.MinReplicas < 1 {
+			v.add("spec.autoscaler.minReplicas", "%d must be at least 1", a.MinReplicas)
+		}
+		if a.MaxReplicas < a.MinReplicas {
+			v.add("spec.autoscaler.maxReplicas", "%d must not be less than minReplicas %d", a.MaxReplicas, a.MinReplicas)
+		}
+		if a.TargetCPUUtilizationPercentage < 1 {
+			v.add("spec.autoscaler.targetCPUUtilizationPercentage", "%d must be at least 1", a.TargetCPUUtilizationPercentage)
+		}
+	}
+	switch dc.Spec.Strategy.Type {
+	case "", DeploymentStrategyTypeRolling, DeploymentStrategyTypeRecreate, DeploymentStrategyTypeCustom:
+	default:
+		v.add("spec.strategy.type", "%s must be Rolling, Recreate or Custom", dc.Spec.Strategy.Type)
+	}
+	if dc.Spec.Replicas < 0 {
+		v.add("spec.replicas", "%d must not be negative", dc.Spec.Replicas)
+	}
+	if nil == dc.Spec.Template {
+		v.add("spec.template", "is required")
+		return v.err()
+	}
+	if !dc.SelectedBy(dc.Spec.Selector) {
+		v.add("spec.selector", "must match the labels of spec.template.metadata.labels")
+	}
+	validatePodSpec(v, "spec.template.spec", dc.Spec.Template.Spec)
+	return v.err()
+}
+
+func validatePodSpec(v *validator, field string, spec k8.PodSpec) {
+	if len(spec.Containers) == 0 {
+		v.add(field+".containers", "at least one container is required")
+	}
+	volumes := make(map[string]bool, len(spec.Volumes))
+	for i, vol := range spec.Volumes {
+		if "" == vol.Name {
+			v.add(fmt.Sprintf("%s.volumes[%d].name", field, i), "is required")
+		}
+		volumes[vol.Name] = true
+	}
+	names := make(map[string]bool, len(spec.Containers))
+	for i, c := range spec.Containers {
+		containerField := fmt.Sprintf("%s.containers[%d]", field, i)
+		if "" == c.Name {
+			v.add(containerField+".name", "is required")
+		} else if names[c.Name] {
+			v.add(containerField+".name", "%s is used by another container", c.Name)
+		}
+		names[c.Name] = true
+		if "" == c.Image {
+			v.add(containerField+".image", "is required")
+		}
+		for j, p := range c.Ports {
+			v.port(fmt.Sprintf("%s.ports[%d].containerPort", containerField, j), p.ContainerPort)
+			if p.Protocol != "" && p.Protocol != k8.ProtocolTCP && p.Protocol != k8.ProtocolUDP {
+				v.add(fmt.Sprintf("%s.ports[%d].protocol", containerField, j), "%s must be TCP or UDP", p.Protocol)
+			}
+		}
+		for j, m := range c.VolumeMounts {
+			if !volumes[m.Name] {
+				v.add(fmt.Sprintf("%s.volumeMounts[%d].name", containerField, j), "%s is not a volume of the pod", m.Name)
+			}
+		}
+		for j, e := range c.Env {
+			if "" == e.Name {
+				v.add(fmt.Sprintf("%s.env[%d].name", containerField, j), "is required")
+			}
+		}
+	}
+}
+
+// ValidateService checks a stored service can be generated
+func ValidateService(s *k8.Service) error {
+	v := &validator{}
+	v.name("metadata.name", s.Name)
+	switch s.Spec.Type {
+	case "", k8.ServiceTypeClusterIP, k8.ServiceTypeNodePort, k8.ServiceTypeLoadBalancer:
+	default:
+		v.add("spec.type", "%s must be ClusterIP, NodePort or LoadBalancer", s.Spec.Type)
+	}
+	names := make(map[string]bool, len(s.Spec.Ports))
+	for i, p := range s.Spec.Ports {
+		field := fmt.Sprintf("spec.ports[%d]", i)
+		v.port(field+".port", p.Port)
+		if len(s.Spec.Ports) > 1 && "" == p.Name {
+			v.add(field+".name", "is required when a service has more than one port")
+		}
+		if "" != p.Name && names[p.Name] {
+			v.add(field+".name", "%s is used by another port", p.Name)
+		}
+		names[p.Name] = true
+		if p.TargetPort.Type == intstr.Int && p.TargetPort.IntVal != 0 {
+			v.port(field+".targetPort", p.TargetPort.IntVal)
+		}
+		if p.Protocol != "" && p.Protocol != k8.ProtocolTCP && p.Protocol != k8.ProtocolUDP {
+			v.add(field+".protocol", "%s must be TCP or UDP", p.Protocol)
+		}
+	}
+	return v.err()
+}
+
+// ValidateRoute checks a stored route can be generated
+func ValidateRoute(r *Route) error {
+	v := &validator{}
+	v.name("metadata.name", r.Name)
+	if "" == r.Spec.To.Name {
+		v.add("spec.to.name", "is required")
+	}
+	if "" != r.Spec.To.Kind && "Service" != r.Spec.To.Kind {
+		v.add("spec.to.kind", "%s must be Service", r.Spec.To.Kind)
+	}
+	if "" != r.Spec.Path && !strings.HasPrefix(r.Spec.Path, "/") {
+		v.add("spec.path", "%s must start with /", r.Spec.Path)
+	}
+	return v.err()
+}
+
+// ValidatePersistentVolumeClaim checks a stored claim can be generated
+func ValidatePersistentVolumeClaim(pvc *k8.PersistentVolumeClaim) error {
+	v := &validator{}
+	v.name("metadata.name", pvc.Name)
+	if len(pvc.Spec.AccessModes) == 0 {
+		v.add("spec.accessModes", "at least one access mode is required")
+	}
+	for i, m := range pvc.Spec.AccessModes {
+		switch m {
+		case k8.ReadWriteOnce, k8.ReadOnlyMany, k8.ReadWriteMany:
+		default:
+			v.add(fmt.Sprintf("spec.accessModes[%d]", i), "%s must be ReadWriteOnce, ReadOnlyMany or ReadWriteMany", m)
+		}
+	}
+	if q, ok := pvc.Spec.Resources.Requests[k8.ResourceStorage]; !ok || q.Sign() <= 0 {
+		v.add("spec.resources.requests.storage", "a storage size is required")
+	}
+	return v.err()
+}
+
+// ValidateParameter checks a parameter can be referenced from the template
+func ValidateParameter(p *Parameter) error {
+	v := &validator{}
+	if "" == p.Name {
+		v.add("name", "is required")
+	} else if !parameterName.MatchString(p.Name) {
+		v.add("name", "%s must be letters, numbers and '_'", p.Name)
+	}
+	if "" != p.Generate && "expression" != p.Generate {
+		v.add("generate", "%s must be expression", p.Generate)
+	}
+	return v.err()
+}
+
+// ValidateTemplate checks every object of a template, prefixing fields with where the object is kept
+func ValidateTemplate(appTemp *ApplicationTemplate) error {
+	v := &validator{}
+	v.name("metadata.name", appTemp.Name)
+	nested := func(prefix string, err error) {
+		if errs, ok := err.(ValidationError); ok {
+			for _, fe := range errs {
+				v.add(prefix+"."+fe.Field, "%s", fe.Message)
+			}
+		}
+	}
+	for _, k := range sortedNames(appTemp.DeploymentConfigs) {
+		nested(fmt.Sprintf("deploymentConfigs[%s]", k), ValidateDeploymentConfig(appTemp.DeploymentConfigs[k]))
+	}
+	for _, k := range sortedNames(appTemp.Services) {
+		nested(fmt.Sprintf("services[%s]", k), ValidateService(appTemp.Services[k]))
+	}
+	for _, k := range sortedNames(appTemp.Routes) {
+		nested(fmt.Sprintf("routes[%s]", k), ValidateRoute(appTemp.Routes[k]))
+	}
+	for _, k := range sortedNames(appTemp.PersistentVolumes) {
+		nested(fmt.Sprintf("persistentVolumes[%s]", k), ValidatePersistentVolumeClaim(appTemp.PersistentVolumes[k]))
+	}
+	params := make(map[string]bool, len(appTemp.Parameters))
+	for i, p := range appTemp.Parameters {
+		nested(fmt.Sprintf("parameters[%d]", i), ValidateParameter(p))
+		if "" != p.Name && params[p.Name] {
+			v.add(fmt.Sprintf("parameters[%d].name", i), "%s is used by another parameter", p.Name)
+		}
+		params[p.Name] = true
+	}
+	return v.err()
+}
+
+func sortedNames(objects interface{}) []string {
+	var names []string
+	switch o := objects.(type) {
+	case map[string]*OSTDeploymentConfig:
+		for k := range o {
+			names = append(names, k)
+		}
+	case map[string]*k8.Service:
+		for k := range o {
+			names = append(names, k)
+		}
+	case map[string]*Route:
+		for k := range o {
+			names = append(names, k)
+		}
+	case map[string]*k8.PersistentVolumeClaim:
+		for k := range o {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
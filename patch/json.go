@@ -0,0 +1,203 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation is one operation of a json patch (RFC 6902)
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ApplyJSON applies the operations of a json patch to doc in order and returns the result. doc is changed in place
+func ApplyJSON(doc interface{}, ops []Operation) (interface{}, error) {
+	var err error
+	for i, op := range ops {
+		switch op.Op {
+		case "add":
+			doc, err = pointerAdd(doc, op.Path, op.Value)
+		case "remove":
+			doc, _, err = pointerRemove(doc, op.Path)
+		case "replace":
+			if doc, _, err = pointerRemove(doc, op.Path); err == nil {
+				doc, err = pointerAdd(doc, op.Path, op.Value)
+			}
+		case "move":
+			var value interface{}
+			if doc, value, err = pointerRemove(doc, op.From); err == nil {
+				doc, err = pointerAdd(doc, op.Path, value)
+			}
+		case "copy":
+			var value interface{}
+			if value, err = pointerGet(doc, op.From); err == nil {
+				doc, err = pointerAdd(doc, op.Path, deepCopy(value))
+			}
+		case "test":
+			var value interface{}
+			if value, err = pointerGet(doc, op.Path); err == nil && !equalJSON(value, op.Value) {
+				err = fmt.Errorf("test failed, %s is %v not %v", op.Path, value, op.Value)
+			}
+		default:
+			err = fmt.Errorf("unsupported op %s", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("json patch operation %d %s %s failed %s", i, op.Op, op.Path, err.Error())
+		}
+	}
+	return doc, nil
+}
+
+// pointerTokens splits a json pointer (RFC 6901) into its unescaped reference tokens
+func pointerTokens(pointer string) ([]string, error) {
+	if "" == pointer {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer %s must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, t := range tokens {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			v, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("no field %s", t)
+			}
+			current = v
+		case []interface{}:
+			i, err := strconv.Atoi(t)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, fmt.Errorf("no index %s", t)
+			}
+			current = c[i]
+		default:
+			return nil, fmt.Errorf("cannot look up %s in a value", t)
+		}
+	}
+	return current, nil
+}
+
+// pointerAdd adds value at pointer. The parent must exist. Adding to a list inserts, with - appending
+func pointerAdd(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := pointerGet(doc, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+		return doc, nil
+	case []interface{}:
+		i := len(p)
+		if "-" != last {
+			if i, err = strconv.Atoi(last); err != nil || i < 0 || i > len(p) {
+				return nil, fmt.Errorf("no index %s", last)
+			}
+		}
+		list := append(p[:i:i], append([]interface{}{value}, p[i:]...)...)
+		return pointerReplaceList(doc, parentPointer, list)
+	}
+	return nil, fmt.Errorf("cannot add %s to a value", last)
+}
+
+// pointerRemove removes the value at pointer and returns it
+func pointerRemove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	value, err := pointerGet(doc, pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, _ := pointerGet(doc, parentPointer)
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		delete(p, last)
+		return doc, value, nil
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		doc, err = pointerReplaceList(doc, parentPointer, append(p[:i:i], p[i+1:]...))
+		return doc, value, err
+	}
+	return nil, nil, fmt.Errorf("cannot remove %s from a value", last)
+}
+
+// pointerReplaceList puts a list that changed length back into its parent
+func pointerReplaceList(doc interface{}, pointer string, list []interface{}) (interface{}, error) {
+	tokens, _ := pointerTokens(pointer)
+	if len(tokens) == 0 {
+		return list, nil
+	}
+	parent, err := pointerGet(doc, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = list
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		p[i] = list
+	}
+	return doc, nil
+}
+
+func deepCopy(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var copied interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	decoder.Decode(&copied)
+	return copied
+}
+
+// equalJSON compares two values by their json form so numbers decoded differently still match
+func equalJSON(a, b interface{}) bool {
+	var ga, gb interface{}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	json.Unmarshal(ja, &ga)
+	json.Unmarshal(jb, &gb)
+	return reflect.DeepEqual(ga, gb)
+}
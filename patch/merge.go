@@ -0,0 +1,22 @@
+package patch
+
+// Merge applies a json merge patch (RFC 7386) to doc and returns the result. Objects are merged, null removes a
+// field and anything else replaces the value
+func Merge(doc, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docObject, ok := doc.(map[string]interface{})
+	if !ok {
+		docObject = map[string]interface{}{}
+	}
+	for k, v := range patchObject {
+		if nil == v {
+			delete(docObject, k)
+			continue
+		}
+		docObject[k] = Merge(docObject[k], v)
+	}
+	return docObject
+}
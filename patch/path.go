@@ -0,0 +1,179 @@
+// Package patch changes objects in their generic json form by field path, json merge patch or json patch
+package patch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is one part of a field path: a field name, a list index or a list element selected by one of its fields
+type step struct {
+	field    string
+	index    int
+	selector []string
+}
+
+func (s step) isIndex() bool {
+	return "" == s.field
+}
+
+// parsePath parses paths such as spec.replicas, spec.template.spec.containers[0].image or
+// spec.template.spec.containers[name=web].image
+func parsePath(path string) ([]step, error) {
+	var steps []step
+	rest := path
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed [ in path %s", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if keyVal := strings.SplitN(inner, "=", 2); len(keyVal) == 2 {
+				steps = append(steps, step{selector: keyVal})
+				continue
+			}
+			i, err := strconv.Atoi(inner)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("expected an index or key=value in [%s] in path %s", inner, path)
+			}
+			steps = append(steps, step{index: i})
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			steps = append(steps, step{field: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return steps, nil
+}
+
+// child returns the value a step selects from current
+func child(current interface{}, s step, path string) (interface{}, error) {
+	if !s.isIndex() {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an object at %s", path, s.field)
+		}
+		return m[s.field], nil
+	}
+	list, ok := current.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list", path)
+	}
+	i, err := listIndex(list, s, path)
+	if err != nil {
+		return nil, err
+	}
+	return list[i], nil
+}
+
+func listIndex(list []interface{}, s step, path string) (int, error) {
+	if nil == s.selector {
+		if s.index >= len(list) {
+			return 0, fmt.Errorf("index %d is out of range in %s which has %d items", s.index, path, len(list))
+		}
+		return s.index, nil
+	}
+	for i, item := range list {
+		if m, ok := item.(map[string]interface{}); ok && fmt.Sprint(m[s.selector[0]]) == s.selector[1] {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no item with %s=%s in %s", s.selector[0], s.selector[1], path)
+}
+
+// Set sets the field at path in doc to value creating any missing objects on the way. List items must exist
+func Set(doc map[string]interface{}, path string, value interface{}) error {
+	steps, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	var current interface{} = doc
+	for i, s := range steps[:len(steps)-1] {
+		next, err := child(current, s, path)
+		if err != nil {
+			return err
+		}
+		if nil == next {
+			if steps[i+1].isIndex() {
+				return fmt.Errorf("%s has no list at %s", path, s.field)
+			}
+			next = map[string]interface{}{}
+			current.(map[string]interface{})[s.field] = next
+		}
+		current = next
+	}
+	return assign(current, steps[len(steps)-1], value, path)
+}
+
+// Remove removes the field or list item at path from doc
+func Remove(doc map[string]interface{}, path string) error {
+	steps, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	var current interface{} = doc
+	parents := []interface{}{}
+	for _, s := range steps[:len(steps)-1] {
+		parents = append(parents, current)
+		if current, err = child(current, s, path); err != nil {
+			return err
+		}
+		if nil == current {
+			return nil
+		}
+	}
+	last := steps[len(steps)-1]
+	if !last.isIndex() {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object at %s", path, last.field)
+		}
+		delete(m, last.field)
+		return nil
+	}
+	list, ok := current.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not a list", path)
+	}
+	i, err := listIndex(list, last, path)
+	if err != nil {
+		return err
+	}
+	list = append(list[:i:i], list[i+1:]...)
+	//the shortened list replaces the old one in its parent
+	parentStep := steps[len(steps)-2]
+	return assign(parents[len(parents)-1], parentStep, list, path)
+}
+
+func assign(current interface{}, s step, value interface{}, path string) error {
+	if !s.isIndex() {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object at %s", path, s.field)
+		}
+		m[s.field] = value
+		return nil
+	}
+	list, ok := current.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not a list", path)
+	}
+	i, err := listIndex(list, s, path)
+	if err != nil {
+		return err
+	}
+	list[i] = value
+	return nil
+}
@@ -0,0 +1,134 @@
+// Package secret encrypts sensitive template values at rest with a local AES-GCM key so that the store can be committed
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ENCRYPTED_PREFIX marks a stored value as encrypted. Values without it are plain text
+	ENCRYPTED_PREFIX = "templator:enc:v1:"
+	// MASK replaces encrypted values when they are read
+	MASK     = "******"
+	KEY_SIZE = 32
+)
+
+// KeyLocation is the key file used to encrypt and decrypt values. It is set from the --key-file flag
+var KeyLocation = DefaultKeyLocation()
+
+// DefaultKeyLocation keeps the key in the home directory so that it is not committed along with the store
+func DefaultKeyLocation() string {
+	return filepath.Join(os.Getenv("HOME"), ".templator", "key")
+}
+
+// IsEncrypted reports whether a stored value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ENCRYPTED_PREFIX)
+}
+
+// NewKey returns a new random key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KEY_SIZE)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LoadKey reads the key at location. A missing key is created when create is set
+func LoadKey(location string, create bool) ([]byte, error) {
+	data, err := ioutil.ReadFile(location)
+	if os.IsNotExist(err) && create {
+		key, err := NewKey()
+		if err != nil {
+			return nil, err
+		}
+		return key, WriteKey(location, key)
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no key file at %s to decrypt sensitive values with", location)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != KEY_SIZE {
+		return nil, fmt.Errorf("key file %s does not hold a %d byte base64 key", location, KEY_SIZE)
+	}
+	return key, nil
+}
+
+// WriteKey replaces the key at location with key, readable only by the current user
+func WriteKey(location string, key []byte) error {
+	if err := os.MkdirAll(filepath.Dir(location), 0700); err != nil {
+		return err
+	}
+	tmp := location + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, location)
+}
+
+// Encrypt returns value encrypted with key in its stored form
+func Encrypt(key []byte, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return ENCRYPTED_PREFIX + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plain text of a stored value. Values that are not encrypted are returned as they are
+func Decrypt(key []byte, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, ENCRYPTED_PREFIX))
+	if err != nil {
+		return "", fmt.Errorf("encrypted value is not valid base64 %s", err.Error())
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("encrypted value is too short")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value, it may have been encrypted with a different key")
+	}
+	return string(plain), nil
+}
+
+// EncryptValue encrypts value with the key at KeyLocation, creating the key the first time
+func EncryptValue(value string) (string, error) {
+	key, err := LoadKey(KeyLocation, true)
+	if err != nil {
+		return "", err
+	}
+	return Encrypt(key, value)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
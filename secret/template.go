@@ -0,0 +1,152 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/maleck13/templator/model"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// values calls visit with every value of appTemp that can be sensitive: container and hook env values and parameter
+// values. path names where the value is by names rather than positions so that it survives reordering
+func values(appTemp *model.ApplicationTemplate, visit func(path string, value *string) error) error {
+	visitEnv := func(path string, env []k8.EnvVar) error {
+		for i := range env {
+			if err := visit(fmt.Sprintf("%s.env[%s]", path, env[i].Name), &env[i].Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for k, dc := range appTemp.DeploymentConfigs {
+		path := fmt.Sprintf("deploymentConfigs[%s]", k)
+		if dc.Spec.Template != nil {
+			for i, c := range dc.Spec.Template.Spec.Containers {
+				if err := visitEnv(fmt.Sprintf("%s.containers[%s]", path, c.Name), dc.Spec.Template.Spec.Containers[i].Env); err != nil {
+					return err
+				}
+			}
+		}
+		hooks := make(map[string]*model.LifecycleHook)
+		if p := dc.Spec.Strategy.RollingParams; p != nil {
+			hooks["rollingParams.pre"], hooks["rollingParams.post"] = p.Pre, p.Post
+		}
+		if p := dc.Spec.Strategy.RecreateParams; p != nil {
+			hooks["recreateParams.pre"], hooks["recreateParams.mid"], hooks["recreateParams.post"] = p.Pre, p.Mid, p.Post
+		}
+		for name, h := range hooks {
+			if h != nil && h.ExecNewPod != nil {
+				if err := visitEnv(path+".strategy."+name, h.ExecNewPod.Env); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, p := range appTemp.Parameters {
+		if err := visit(fmt.Sprintf("parameters[%s]", p.Name), &p.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasEncrypted reports whether appTemp holds any encrypted values
+func HasEncrypted(appTemp *model.ApplicationTemplate) bool {
+	found := false
+	values(appTemp, func(_ string, value *string) error {
+		found = found || IsEncrypted(*value)
+		return nil
+	})
+	return found
+}
+
+// DecryptTemplate replaces the encrypted values of appTemp with their plain text using the key at KeyLocation. The
+// key is only needed when there are encrypted values
+func DecryptTemplate(appTemp *model.ApplicationTemplate) error {
+	if !HasEncrypted(appTemp) {
+		return nil
+	}
+	key, err := LoadKey(KeyLocation, false)
+	if err != nil {
+		return err
+	}
+	return values(appTemp, func(_ string, value *string) error {
+		plain, err := Decrypt(key, *value)
+		if err != nil {
+			return err
+		}
+		*value = plain
+		return nil
+	})
+}
+
+// MaskTemplate replaces the encrypted values of appTemp with MASK so that they can be shown
+func MaskTemplate(appTemp *model.ApplicationTemplate) {
+	values(appTemp, func(_ string, value *string) error {
+		if IsEncrypted(*value) {
+			*value = MASK
+		}
+		return nil
+	})
+}
+
+// ReencryptTemplate decrypts the encrypted values of appTemp with oldKey and encrypts them again with newKey
+func ReencryptTemplate(appTemp *model.ApplicationTemplate, oldKey, newKey []byte) error {
+	return values(appTemp, func(_ string, value *string) error {
+		if !IsEncrypted(*value) {
+			return nil
+		}
+		plain, err := Decrypt(oldKey, *value)
+		if err != nil {
+			return err
+		}
+		*value, err = Encrypt(newKey, plain)
+		return err
+	})
+}
+
+// SealTemplate is called on every template about to be saved so that no write stores a sensitive value in plain
+// text. A value of updated that was encrypted in previous at the same place is encrypted again. It gets its stored
+// form back when it is left as it was read, decrypted or as MASK, and is encrypted anew when it was changed. previous
+// is the stored template updated was made from and nil for a new template
+func SealTemplate(previous, updated *model.ApplicationTemplate) error {
+	stored := make(map[string]string)
+	if previous != nil {
+		values(previous, func(path string, value *string) error {
+			if IsEncrypted(*value) {
+				stored[path] = *value
+			}
+			return nil
+		})
+	}
+	var key []byte
+	return values(updated, func(path string, value *string) error {
+		if IsEncrypted(*value) {
+			return nil
+		}
+		encrypted, ok := stored[path]
+		if !ok {
+			if MASK == *value {
+				return fmt.Errorf("%s is %s but there is no stored value it masks", path, MASK)
+			}
+			return nil
+		}
+		if MASK == *value {
+			*value = encrypted
+			return nil
+		}
+		if nil == key {
+			var err error
+			if key, err = LoadKey(KeyLocation, false); err != nil {
+				return err
+			}
+		}
+		if plain, err := Decrypt(key, encrypted); err == nil && plain == *value {
+			*value = encrypted
+			return nil
+		}
+		var err error
+		*value, err = Encrypt(key, *value)
+		return err
+	})
+}
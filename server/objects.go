@@ -0,0 +1,204 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/maleck13/templator/model"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// objectKind is how the API reaches one kind of object kept in a template
+type objectKind struct {
+	singular string
+	new      func() interface{}
+	name     func(obj interface{}) string
+	validate func(obj interface{}) error
+	list     func(appTemp *model.ApplicationTemplate) interface{}
+	get      func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool)
+	put      func(appTemp *model.ApplicationTemplate, obj interface{})
+	remove   func(appTemp *model.ApplicationTemplate, name string) bool
+}
+
+// objects are stored under their name so that the name in the url is the name in the store
+var kinds = map[string]objectKind{
+	"deployments": {
+		singular: "deployment",
+		new:      func() interface{} { return &model.OSTDeploymentConfig{} },
+		name:     func(obj interface{}) string { return obj.(*model.OSTDeploymentConfig).Name },
+		validate: func(obj interface{}) error {
+			return model.ValidateDeploymentConfig(obj.(*model.OSTDeploymentConfig))
+		},
+		list: func(appTemp *model.ApplicationTemplate) interface{} {
+			list := make([]*model.OSTDeploymentConfig, 0, len(appTemp.DeploymentConfigs))
+			for _, k := range sortedKeys(appTemp.DeploymentConfigs) {
+				list = append(list, appTemp.DeploymentConfigs[k])
+			}
+			return list
+		},
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			dc, ok := appTemp.DeploymentConfigs[name]
+			return dc, ok
+		},
+		put: func(appTemp *model.ApplicationTemplate, obj interface{}) {
+			dc := obj.(*model.OSTDeploymentConfig)
+			if nil == appTemp.DeploymentConfigs {
+				appTemp.DeploymentConfigs = make(map[string]*model.OSTDeploymentConfig)
+			}
+			appTemp.DeploymentConfigs[dc.Name] = dc
+		},
+		remove: func(appTemp *model.ApplicationTemplate, name string) bool {
+			_, ok := appTemp.DeploymentConfigs[name]
+			delete(appTemp.DeploymentConfigs, name)
+			return ok
+		},
+	},
+	"services": {
+		singular: "service",
+		new:      func() interface{} { return &k8.Service{} },
+		name:     func(obj interface{}) string { return obj.(*k8.Service).Name },
+		validate: func(obj interface{}) error {
+			return model.ValidateService(obj.(*k8.Service))
+		},
+		list: func(appTemp *model.ApplicationTemplate) interface{} {
+			list := make([]*k8.Service, 0, len(appTemp.Services))
+			for _, k := range sortedKeys(appTemp.Services) {
+				list = append(list, appTemp.Services[k])
+			}
+			return list
+		},
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			s, ok := appTemp.Services[name]
+			return s, ok
+		},
+		put: func(appTemp *model.ApplicationTemplate, obj interface{}) {
+			s := obj.(*k8.Service)
+			if nil == appTemp.Services {
+				appTemp.Services = make(map[string]*k8.Service)
+			}
+			appTemp.Services[s.Name] = s
+		},
+		remove: func(appTemp *model.ApplicationTemplate, name string) bool {
+			_, ok := appTemp.Services[name]
+			delete(appTemp.Services, name)
+			return ok
+		},
+	},
+	"routes": {
+		singular: "route",
+		new:      func() interface{} { return &model.Route{} },
+		name:     func(obj interface{}) string { return obj.(*model.Route).Name },
+		validate: func(obj interface{}) error {
+			return model.ValidateRoute(obj.(*model.Route))
+		},
+		list: func(appTemp *model.ApplicationTemplate) interface{} {
+			list := make([]*model.Route, 0, len(appTemp.Routes))
+			for _, k := range sortedKeys(appTemp.Routes) {
+				list = append(list, appTemp.Routes[k])
+			}
+			return list
+		},
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			r, ok := appTemp.Routes[name]
+			return r, ok
+		},
+		put: func(appTemp *model.ApplicationTemplate, obj interface{}) {
+			r := obj.(*model.Route)
+			if nil == appTemp.Routes {
+				appTemp.Routes = make(map[string]*model.Route)
+			}
+			appTemp.Routes[r.Name] = r
+		},
+		remove: func(appTemp *model.ApplicationTemplate, name string) bool {
+			_, ok := appTemp.Routes[name]
+			delete(appTemp.Routes, name)
+			return ok
+		},
+	},
+	"volumes": {
+		singular: "volume",
+		new:      func() interface{} { return &k8.PersistentVolumeClaim{} },
+		name:     func(obj interface{}) string { return obj.(*k8.PersistentVolumeClaim).Name },
+		validate: func(obj interface{}) error {
+			return model.ValidatePersistentVolumeClaim(obj.(*k8.PersistentVolumeClaim))
+		},
+		list: func(appTemp *model.ApplicationTemplate) interface{} {
+			list := make([]*k8.PersistentVolumeClaim, 0, len(appTemp.PersistentVolumes))
+			for _, k := range sortedKeys(appTemp.PersistentVolumes) {
+				list = append(list, appTemp.PersistentVolumes[k])
+			}
+			return list
+		},
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			pvc, ok := appTemp.PersistentVolumes[name]
+			return pvc, ok
+		},
+		put: func(appTemp *model.ApplicationTemplate, obj interface{}) {
+			pvc := obj.(*k8.PersistentVolumeClaim)
+			if nil == appTemp.PersistentVolumes {
+				appTemp.PersistentVolumes = make(map[string]*k8.PersistentVolumeClaim)
+			}
+			appTemp.PersistentVolumes[pvc.Name] = pvc
+		},
+		remove: func(appTemp *model.ApplicationTemplate, name string) bool {
+			_, ok := appTemp.PersistentVolumes[name]
+			delete(appTemp.PersistentVolumes, name)
+			return ok
+		},
+	},
+	"parameters": {
+		singular: "parameter",
+		new:      func() interface{} { return &model.Parameter{} },
+		name:     func(obj interface{}) string { return obj.(*model.Parameter).Name },
+		validate: func(obj interface{}) error {
+			return model.ValidateParameter(obj.(*model.Parameter))
+		},
+		list: func(appTemp *model.ApplicationTemplate) interface{} {
+			list := make([]*model.Parameter, 0, len(appTemp.Parameters))
+			return append(list, appTemp.Parameters...)
+		},
+		get: func(appTemp *model.ApplicationTemplate, name string) (interface{}, bool) {
+			for _, p := range appTemp.Parameters {
+				if p.Name == name {
+					return p, true
+				}
+			}
+			return nil, false
+		},
+		put: func(appTemp *model.ApplicationTemplate, obj interface{}) {
+			appTemp.Parameters = append(appTemp.Parameters, obj.(*model.Parameter))
+		},
+		remove: func(appTemp *model.ApplicationTemplate, name string) bool {
+			for i, p := range appTemp.Parameters {
+				if p.Name == name {
+					appTemp.Parameters = append(appTemp.Parameters[:i], appTemp.Parameters[i+1:]...)
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+func sortedKeys(objects interface{}) []string {
+	var keys []string
+	switch o := objects.(type) {
+	case map[string]*model.OSTDeploymentConfig:
+		for k := range o {
+			keys = append(keys, k)
+		}
+	case map[string]*k8.Service:
+		for k := range o {
+			keys = append(keys, k)
+		}
+	case map[string]*model.Route:
+		for k := range o {
+			keys = append(keys, k)
+		}
+	case map[string]*k8.PersistentVolumeClaim:
+		for k := range o {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
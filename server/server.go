@@ -0,0 +1,432 @@
+// Package server exposes the template store as a REST/JSON API
+//
+//	GET    /templates                              list templates
+//	POST   /templates                              create a template
+//	GET    /templates/{name}                       get a template
+//	DELETE /templates/{name}                       delete a template
+//	GET    /templates/{name}/{kind}                list the objects of a kind
+//	POST   /templates/{name}/{kind}                add an object
+//	GET    /templates/{name}/{kind}/{object}       get an object
+//	DELETE /templates/{name}/{kind}/{object}       delete an object
+//	POST   /templates/{name}/generate              generate the template with the GenerateRequest options
+//
+// kind is one of deployments, services, routes, volumes or parameters. Sensitive values are masked on the way out,
+// kept encrypted on the way in and decrypted only to generate
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/maleck13/templator/generate"
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/secret"
+	"github.com/maleck13/templator/service"
+)
+
+// MAX_BODY is the largest request body read, a template is far smaller
+const MAX_BODY = 4 << 20
+
+// GenerateRequest is the body of a generate request. Profile is applied first and any other option given overrides it.
+// Target is openshift or kubernetes, the kubernetes objects are returned in the template like the openshift ones
+type GenerateRequest struct {
+	Profile      string            `json:"profile,omitempty"`
+	Nodes        *int              `json:"nodes,omitempty"`
+	Storage      *bool             `json:"storage,omitempty"`
+	NodeSelector *bool             `json:"nodeSelector,omitempty"`
+	Namespace    *string           `json:"namespace,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Order        []string          `json:"order,omitempty"`
+	Target       *string           `json:"target,omitempty"`
+}
+
+// GenerateResponse holds the generated template and anything that could not be generated as asked
+type GenerateResponse struct {
+	Template *model.Template `json:"template"`
+	Warnings []string        `json:"warnings"`
+}
+
+// ErrorResponse is the body of every failed request. Fields are set when the request failed validation
+type ErrorResponse struct {
+	Error  string             `json:"error"`
+	Fields []model.FieldError `json:"fields,omitempty"`
+}
+
+// TemplateSummary is an entry of the template list
+type TemplateSummary struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	ResourceVersion   string `json:"resourceVersion,omitempty"`
+	DeploymentConfigs int    `json:"deploymentConfigs"`
+	Services          int    `json:"services"`
+	Routes            int    `json:"routes"`
+	Volumes           int    `json:"volumes"`
+	Parameters        int    `json:"parameters"`
+}
+
+// httpError is an error with the status it is returned with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (he *httpError) Error() string {
+	return he.err.Error()
+}
+
+func newHTTPError(status int, format string, args ...interface{}) error {
+	return &httpError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// Server handles the API requests against a template store
+type Server struct {
+	templates *service.TemplateService
+}
+
+// NewHandler returns the API handler for the store behind templates
+func NewHandler(templates *service.TemplateService) http.Handler {
+	return &Server{templates: templates}
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(req.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if "" == path || "templates" != parts[0] || len(parts) > 4 {
+		writeError(rw, newHTTPError(http.StatusNotFound, "no such resource %s", req.URL.Path))
+		return
+	}
+	for _, p := range parts[1:] {
+		if "" == p {
+			writeError(rw, newHTTPError(http.StatusNotFound, "no such resource %s", req.URL.Path))
+			return
+		}
+	}
+	var (
+		status = http.StatusOK
+		body   interface{}
+		err    error
+	)
+	switch {
+	case len(parts) == 1 && "GET" == req.Method:
+		body, err = s.listTemplates()
+	case len(parts) == 1 && "POST" == req.Method:
+		status = http.StatusCreated
+		body, err = s.createTemplate(req)
+	case len(parts) == 2 && "GET" == req.Method:
+		body, err = s.getTemplate(parts[1])
+	case len(parts) == 2 && "DELETE" == req.Method:
+		status = http.StatusNoContent
+		err = s.deleteTemplate(parts[1])
+	case len(parts) == 3 && "generate" == parts[2] && "POST" == req.Method:
+		body, err = s.generate(parts[1], req)
+	case len(parts) == 3 && "GET" == req.Method:
+		body, err = s.listObjects(parts[1], parts[2])
+	case len(parts) == 3 && "POST" == req.Method:
+		status = http.StatusCreated
+		body, err = s.createObject(parts[1], parts[2], req)
+	case len(parts) == 4 && "GET" == req.Method:
+		body, err = s.getObject(parts[1], parts[2], parts[3])
+	case len(parts) == 4 && "DELETE" == req.Method:
+		status = http.StatusNoContent
+		err = s.deleteObject(parts[1], parts[2], parts[3])
+	default:
+		err = newHTTPError(http.StatusMethodNotAllowed, "%s is not supported on %s", req.Method, req.URL.Path)
+	}
+	if err != nil {
+		writeError(rw, err)
+		return
+	}
+	writeJSON(rw, status, body)
+}
+
+func (s *Server) listTemplates() ([]TemplateSummary, error) {
+	templates, err := s.templates.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]TemplateSummary, 0, len(templates))
+	for name, t := range templates {
+		summaries = append(summaries, TemplateSummary{
+			Name:              name,
+			Description:       t.Annotations["description"],
+			ResourceVersion:   t.ResourceVersion,
+			DeploymentConfigs: len(t.DeploymentConfigs),
+			Services:          len(t.Services),
+			Routes:            len(t.Routes),
+			Volumes:           len(t.PersistentVolumes),
+			Parameters:        len(t.Parameters),
+		})
+	}
+	sort.Sort(summarySorter(summaries))
+	return summaries, nil
+}
+
+func (s *Server) createTemplate(req *http.Request) (*model.ApplicationTemplate, error) {
+	appTemp := &model.ApplicationTemplate{}
+	if err := decodeBody(req, appTemp); err != nil {
+		return nil, err
+	}
+	//fill in whatever the request left out the way a template created on the command line has it
+	created := model.NewApplicationTemplate(appTemp.Name)
+	if nil == appTemp.Annotations {
+		appTemp.Annotations = created.Annotations
+	}
+	if "" == appTemp.Kind {
+		appTemp.TypeMeta = created.TypeMeta
+	}
+	if nil == appTemp.DeploymentConfigs {
+		appTemp.DeploymentConfigs = created.DeploymentConfigs
+	}
+	if nil == appTemp.Services {
+		appTemp.Services = created.Services
+	}
+	if nil == appTemp.Routes {
+		appTemp.Routes = created.Routes
+	}
+	if nil == appTemp.PersistentVolumes {
+		appTemp.PersistentVolumes = created.PersistentVolumes
+	}
+	if nil == appTemp.Pods {
+		appTemp.Pods = created.Pods
+	}
+	if nil == appTemp.Parameters {
+		appTemp.Parameters = created.Parameters
+	}
+	if err := model.ValidateTemplate(appTemp); err != nil {
+		return nil, err
+	}
+	if err := secret.SealTemplate(nil, appTemp); err != nil {
+		return nil, &httpError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	//a new template has no revision, one given is ignored rather than reported as a conflict
+	appTemp.ResourceVersion = ""
+	if err := s.templates.SaveTemplate(appTemp.Name, appTemp); err != nil {
+		if service.IsConflict(err) {
+			return nil, newHTTPError(http.StatusConflict, "a template named %s already exists", appTemp.Name)
+		}
+		return nil, err
+	}
+	return s.getTemplate(appTemp.Name)
+}
+
+// storedTemplate returns the named template or a not found error
+func (s *Server) storedTemplate(name string) (*model.ApplicationTemplate, error) {
+	appTemp, err := s.templates.GetTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	if nil == appTemp {
+		return nil, newHTTPError(http.StatusNotFound, "no template named %s", name)
+	}
+	return appTemp, nil
+}
+
+func (s *Server) getTemplate(name string) (*model.ApplicationTemplate, error) {
+	appTemp, err := s.storedTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	secret.MaskTemplate(appTemp)
+	return appTemp, nil
+}
+
+func (s *Server) deleteTemplate(name string) error {
+	if _, err := s.storedTemplate(name); err != nil {
+		return err
+	}
+	return s.templates.DeleteTemplate(name)
+}
+
+func (s *Server) listObjects(templateName, kind string) (interface{}, error) {
+	objects, ok := kinds[kind]
+	if !ok {
+		return nil, unknownKind(kind)
+	}
+	appTemp, err := s.getTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	return objects.list(appTemp), nil
+}
+
+func (s *Server) getObject(templateName, kind, name string) (interface{}, error) {
+	objects, ok := kinds[kind]
+	if !ok {
+		return nil, unknownKind(kind)
+	}
+	appTemp, err := s.getTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := objects.get(appTemp, name)
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "no %s named %s in template %s", objects.singular, name, templateName)
+	}
+	return obj, nil
+}
+
+func (s *Server) createObject(templateName, kind string, req *http.Request) (interface{}, error) {
+	objects, ok := kinds[kind]
+	if !ok {
+		return nil, unknownKind(kind)
+	}
+	obj := objects.new()
+	if err := decodeBody(req, obj); err != nil {
+		return nil, err
+	}
+	if err := objects.validate(obj); err != nil {
+		return nil, err
+	}
+	if _, err := s.storedTemplate(templateName); err != nil {
+		return nil, err
+	}
+	name := objects.name(obj)
+	err := s.templates.UpdateTemplate(templateName, func(appTemp *model.ApplicationTemplate) error {
+		if _, exists := objects.get(appTemp, name); exists {
+			return newHTTPError(http.StatusConflict, "a %s named %s already exists in template %s", objects.singular, name, templateName)
+		}
+		previous, err := appTemp.Copy()
+		if err != nil {
+			return err
+		}
+		objects.put(appTemp, obj)
+		if err := secret.SealTemplate(previous, appTemp); err != nil {
+			return &httpError{status: http.StatusUnprocessableEntity, err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getObject(templateName, kind, name)
+}
+
+func (s *Server) deleteObject(templateName, kind, name string) error {
+	objects, ok := kinds[kind]
+	if !ok {
+		return unknownKind(kind)
+	}
+	if _, err := s.storedTemplate(templateName); err != nil {
+		return err
+	}
+	return s.templates.UpdateTemplate(templateName, func(appTemp *model.ApplicationTemplate) error {
+		if !objects.remove(appTemp, name) {
+			return newHTTPError(http.StatusNotFound, "no %s named %s in template %s", objects.singular, name, templateName)
+		}
+		return nil
+	})
+}
+
+func (s *Server) generate(templateName string, req *http.Request) (*GenerateResponse, error) {
+	genReq := &GenerateRequest{}
+	if err := decodeBody(req, genReq); err != nil {
+		return nil, err
+	}
+	if genReq.Nodes != nil && *genReq.Nodes < 0 {
+		return nil, model.ValidationError{{Field: "nodes", Message: fmt.Sprintf("%d must not be negative", *genReq.Nodes)}}
+	}
+	if genReq.Target != nil && generate.TARGET_OPENSHIFT != *genReq.Target && generate.TARGET_KUBERNETES != *genReq.Target {
+		return nil, model.ValidationError{{Field: "target", Message: fmt.Sprintf("%s must be %s or %s", *genReq.Target, generate.TARGET_OPENSHIFT, generate.TARGET_KUBERNETES)}}
+	}
+	appTemp, err := s.storedTemplate(templateName)
+	if err != nil {
+		return nil, err
+	}
+	if err := secret.DecryptTemplate(appTemp); err != nil {
+		return nil, err
+	}
+	opts := generate.Options{}
+	if "" != genReq.Profile {
+		if opts, err = generate.ProfileOptions(appTemp, genReq.Profile); err != nil {
+			return nil, &httpError{status: http.StatusUnprocessableEntity, err: err}
+		}
+	}
+	if genReq.Nodes != nil {
+		opts.Nodes = *genReq.Nodes
+	}
+	if genReq.Storage != nil {
+		opts.Storage = *genReq.Storage
+	}
+	if genReq.NodeSelector != nil {
+		opts.NodeSelector = *genReq.NodeSelector
+	}
+	if genReq.Namespace != nil {
+		opts.Namespace = *genReq.Namespace
+	}
+	if genReq.Target != nil {
+		opts.Target = *genReq.Target
+	}
+	opts.Labels, opts.Annotations = genReq.Labels, genReq.Annotations
+	if len(genReq.Order) > 0 {
+		opts.Order = generate.KindOrder(genReq.Order)
+	}
+	result, err := generate.Generate(appTemp, opts)
+	if err != nil {
+		return nil, err
+	}
+	warnings := result.Warnings
+	if nil == warnings {
+		warnings = []string{}
+	}
+	return &GenerateResponse{Template: result.Template, Warnings: warnings}, nil
+}
+
+func unknownKind(kind string) error {
+	return newHTTPError(http.StatusNotFound, "unknown kind %s expected deployments, services, routes, volumes or parameters", kind)
+}
+
+// decodeBody reads the json body of req into v, rejecting fields v does not have so typos are not silently dropped
+func decodeBody(req *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, MAX_BODY))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return newHTTPError(http.StatusBadRequest, "invalid request body %s", err.Error())
+	}
+	return nil
+}
+
+func writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	if status == http.StatusNoContent {
+		rw.WriteHeader(status)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	encoder := json.NewEncoder(rw)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(body)
+}
+
+func writeError(rw http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	resp := ErrorResponse{Error: err.Error()}
+	switch e := err.(type) {
+	case *httpError:
+		status = e.status
+	case model.ValidationError:
+		status = http.StatusUnprocessableEntity
+		resp.Fields = e
+	case *service.ConflictError:
+		status = http.StatusConflict
+	}
+	writeJSON(rw, status, resp)
+}
+
+type summarySorter []TemplateSummary
+
+func (ss summarySorter) Len() int {
+	return len(ss)
+}
+
+func (ss summarySorter) Swap(i, j int) {
+	ss[i], ss[j] = ss[j], ss[i]
+}
+
+func (ss summarySorter) Less(i, j int) bool {
+	return ss[i].Name < ss[j].Name
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maleck13/templator/model"
+	"github.com/maleck13/templator/secret"
+	"github.com/maleck13/templator/service"
+	k8 "k8s.io/kubernetes/pkg/api/v1"
+)
+
+// newTestServer serves a store and key file in a temp dir that is removed along with the server
+func newTestServer(t *testing.T) (*httptest.Server, *service.TemplateService, func()) {
+	dir, err := ioutil.TempDir("", "templator-server-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyLocation := secret.KeyLocation
+	secret.KeyLocation = filepath.Join(dir, "key")
+	templates := service.NewLocalTemplateService(filepath.Join(dir, "templates.json"))
+	srv := httptest.NewServer(NewHandler(templates))
+	return srv, templates, func() {
+		srv.Close()
+		secret.KeyLocation = keyLocation
+		os.RemoveAll(dir)
+	}
+}
+
+func do(t *testing.T, srv *httptest.Server, method, path string, body interface{}) (int, []byte) {
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, srv.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+func expectStatus(t *testing.T, srv *httptest.Server, method, path string, body interface{}, expected int) []byte {
+	status, data := do(t, srv, method, path, body)
+	if status != expected {
+		t.Fatalf("%s %s expected status %d but got %d %s", method, path, expected, status, data)
+	}
+	return data
+}
+
+// generated is a GenerateResponse with the objects left as json as they cannot be decoded into runtime.Object
+type generated struct {
+	Template struct {
+		Objects []json.RawMessage `json:"objects"`
+	} `json:"template"`
+	Warnings []string `json:"warnings"`
+}
+
+func deployment(name string, env ...k8.EnvVar) *model.OSTDeploymentConfig {
+	dc := model.NewOstDeploymentConfig(name)
+	dc.Spec.Template.Spec.Containers = []k8.Container{{Name: name, Image: "nginx", Env: env}}
+	return dc
+}
+
+func TestTemplates(t *testing.T) {
+	srv, _, done := newTestServer(t)
+	defer done()
+
+	created := &model.ApplicationTemplate{}
+	data := expectStatus(t, srv, "POST", "/templates", `{"metadata":{"name":"app"}}`, http.StatusCreated)
+	if err := json.Unmarshal(data, created); err != nil {
+		t.Fatal(err)
+	}
+	if "app" != created.Name || nil == created.DeploymentConfigs {
+		t.Fatalf("expected the created template app with its maps filled in but got %s", data)
+	}
+	expectStatus(t, srv, "POST", "/templates", `{"metadata":{"name":"app"}}`, http.StatusConflict)
+	expectStatus(t, srv, "POST", "/templates", `{"metadata":{"name":"other"}}`, http.StatusCreated)
+
+	var summaries []TemplateSummary
+	if err := json.Unmarshal(expectStatus(t, srv, "GET", "/templates", nil, http.StatusOK), &summaries); err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || "app" != summaries[0].Name || "other" != summaries[1].Name {
+		t.Fatalf("expected templates app and other but got %+v", summaries)
+	}
+
+	expectStatus(t, srv, "GET", "/templates/app", nil, http.StatusOK)
+	expectStatus(t, srv, "DELETE", "/templates/app", nil, http.StatusNoContent)
+	expectStatus(t, srv, "GET", "/templates/app", nil, http.StatusNotFound)
+	expectStatus(t, srv, "DELETE", "/templates/app", nil, http.StatusNotFound)
+}
+
+func TestRequestErrors(t *testing.T) {
+	srv, _, done := newTestServer(t)
+	defer done()
+	expectStatus(t, srv, "POST", "/templates", `{"metadata":{"name":"app"}}`, http.StatusCreated)
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     interface{}
+		expected int
+	}{
+		{name: "not json", method: "POST", path: "/templates", body: `{"metadata":`, expected: http.StatusBadRequest},
+		{name: "unknown field", method: "POST", path: "/templates", body: `{"metadata":{"name":"x"},"nope":1}`, expected: http.StatusBadRequest},
+		{name: "invalid template", method: "POST", path: "/templates", body: `{"metadata":{"name":"Not Valid"}}`, expected: http.StatusUnprocessableEntity},
+		{name: "invalid object", method: "POST", path: "/templates/app/routes", body: `{"metadata":{"name":"web"}}`, expected: http.StatusUnprocessableEntity},
+		{name: "unknown kind", method: "GET", path: "/templates/app/pods", expected: http.StatusNotFound},
+		{name: "unknown resource", method: "GET", path: "/other", expected: http.StatusNotFound},
+		{name: "missing object", method: "GET", path: "/templates/app/services/web", expected: http.StatusNotFound},
+		{name: "missing template", method: "GET", path: "/templates/missing/services", expected: http.StatusNotFound},
+		{name: "unsupported method", method: "PUT", path: "/templates/app", body: `{}`, expected: http.StatusMethodNotAllowed},
+	}
+	for _, c := range cases {
+		if status, data := do(t, srv, c.method, c.path, c.body); status != c.expected {
+			t.Errorf("%s: expected status %d but got %d %s", c.name, c.expected, status, data)
+		}
+	}
+
+	resp := ErrorResponse{}
+	data := expectStatus(t, srv, "POST", "/templates/app/routes", `{"metadata":{"name":"web"}}`, http.StatusUnprocessableEntity)
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fields) != 1 || "spec.to.name" != resp.Fields[0].Field {
+		t.Fatalf("expected the field spec.to.name to be reported but got %s", data)
+	}
+}
+
+func TestObjects(t *testing.T) {
+	srv, _, done := newTestServer(t)
+	defer done()
+	expectStatus(t, srv, "POST", "/templates", `{"metadata":{"name":"app"}}`, http.StatusCreated)
+
+	expectStatus(t, srv, "POST", "/templates/app/deployments", deployment("web"), http.StatusCreated)
+	expectStatus(t, srv, "POST", "/templates/app/deployments", deployment("web"), http.StatusConflict)
+	expectStatus(t, srv, "POST", "/templates/missing/deployments", deployment("web"), http.StatusNotFound)
+	expectStatus(t, srv, "POST", "/templates/app/deployments", deployment("api"), http.StatusCreated)
+
+	var list []*model.OSTDeploymentConfig
+	if err := json.Unmarshal(expectStatus(t, srv, "GET", "/templates/app/deployments", nil, http.StatusOK), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || "api" != list[0].Name || "web" != list[1].Name {
+		t.Fatalf("expected deployments api and web but got %d", len(list))
+	}
+
+	dc := &model.OSTDeploymentConfig{}
+	if err := json.Unmarshal(expectStatus(t, srv, "GET", "/templates/app/deployments/web", nil, http.StatusOK), dc); err != nil {
+		t.Fatal(err)
+	}
+	if "nginx" != dc.Spec.Template.Spec.Containers[0].Image {
+		t.Fatalf("expected the stored deployment web but got %+v", dc)
+	}
+
+	expectStatus(t, srv, "DELETE", "/templates/app/deployments/web", nil, http.StatusNoContent)
+	expectStatus(t, srv, "GET", "/templates/app/deployments/web", nil, http.StatusNotFound)
+	expectStatus(t, srv, "DELETE", "/templates/app/deployments/web", nil, http.StatusNotFound)
+}
+
+func TestGenerate(t *testing.T) {
+	srv, _, done := newTestServer(t)
+	defer done()
+	expectStatus(t, srv, "POST", "/templates", `{"metadata":{"name":"app"}}`, http.StatusCreated)
+	web := deployment("web")
+	web.Spec.ReplicaStrategy = model.ReplicationStrategy_EqualToNodes
+	expectStatus(t, srv, "POST", "/templates/app/deployments", web, http.StatusCreated)
+
+	resp := generated{}
+	data := expectStatus(t, srv, "POST", "/templates/app/generate", `{"nodes":3}`, http.StatusOK)
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Template.Objects) != 1 || nil == resp.Warnings {
+		t.Fatalf("expected a template with one object and a warnings list but got %s", data)
+	}
+	dc := &model.DeploymentConfig{}
+	if err := json.Unmarshal(resp.Template.Objects[0], dc); err != nil {
+		t.Fatal(err)
+	}
+	if dc.Spec.Replicas != 3 {
+		t.Fatalf("expected 3 replicas for 3 nodes but got %d", dc.Spec.Replicas)
+	}
+
+	data = expectStatus(t, srv, "POST", "/templates/app/generate", `{"nodes":3,"target":"kubernetes"}`, http.StatusOK)
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Template.Objects) != 1 || !strings.Contains(string(resp.Template.Objects[0]), `"kind": "Deployment"`) {
+		t.Fatalf("expected a template with a Deployment for the kubernetes target but got %s", data)
+	}
+
+	expectStatus(t, srv, "POST", "/templates/app/generate", `{"nodes":-1}`, http.StatusUnprocessableEntity)
+	expectStatus(t, srv, "POST", "/templates/app/generate", `{"target":"swarm"}`, http.StatusUnprocessableEntity)
+	expectStatus(t, srv, "POST", "/templates/app/generate", `{"profile":"missing"}`, http.StatusUnprocessableEntity)
+	expectStatus(t, srv, "POST", "/templates/app/generate", `{"nodes":"three"}`, http.StatusBadRequest)
+	expectStatus(t, srv, "POST", "/templates/missing/generate", `{}`, http.StatusNotFound)
+}
+
+func TestEncryptedValues(t *testing.T) {
+	srv, templates, done := newTestServer(t)
+	defer done()
+	encrypted, err := secret.EncryptValue("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	appTemp := model.NewApplicationTemplate("app")
+	appTemp.DeploymentConfigs["web"] = deployment("web", k8.EnvVar{Name: "PASSWORD", Value: encrypted})
+	if err := templates.SaveTemplate("app", appTemp); err != nil {
+		t.Fatal(err)
+	}
+
+	if data := expectStatus(t, srv, "GET", "/templates/app/deployments/web", nil, http.StatusOK); strings.Contains(string(data), "hunter2") || strings.Contains(string(data), secret.ENCRYPTED_PREFIX) {
+		t.Fatalf("expected the password to be masked but got %s", data)
+	}
+	// a masked value read back has nothing stored behind it in a new object
+	expectStatus(t, srv, "POST", "/templates/app/deployments", deployment("api", k8.EnvVar{Name: "PASSWORD", Value: secret.MASK}), http.StatusUnprocessableEntity)
+	expectStatus(t, srv, "POST", "/templates/app/deployments", deployment("api"), http.StatusCreated)
+
+	stored, err := templates.GetTemplate("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := stored.DeploymentConfigs["web"].Spec.Template.Spec.Containers[0].Env[0].Value; value != encrypted {
+		t.Fatalf("expected the stored password to stay encrypted but got %s", value)
+	}
+
+	resp := generated{}
+	if err := json.Unmarshal(expectStatus(t, srv, "POST", "/templates/app/generate", `{}`, http.StatusOK), &resp); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, obj := range resp.Template.Objects {
+		found = found || strings.Contains(string(obj), "hunter2")
+	}
+	if !found {
+		t.Fatal("expected the generated template to hold the decrypted password")
+	}
+}
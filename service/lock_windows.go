@@ -0,0 +1,32 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const lockTimeout = 30 * time.Second
+
+// lockStore creates a lock file beside the store, waiting for any other holder to remove it. Shared locks are not
+// supported so every lock is exclusive
+func lockStore(location string, exclusive bool) (func(), error) {
+	lockFile := location + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+		if err == nil {
+			file.Close()
+			return func() {
+				os.Remove(lockFile)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the store lock %s remove it if no other templator is running", lockFile)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/maleck13/templator/model"
+)
+
+// ConflictError is returned when a template was changed in the store after it was read, or already holds an object
+// that was about to be added to it
+type ConflictError struct {
+	Name     string
+	Expected string
+	Actual   string
+	// Object is set, as "<kind> named <name>", when the template already holds the object being added
+	Object string
+}
+
+func (ce *ConflictError) Error() string {
+	if "" != ce.Object {
+		return fmt.Sprintf("template %s already has a %s, it may have been added by another process", ce.Name, ce.Object)
+	}
+	if "" == ce.Expected {
+		return fmt.Sprintf("template %s already exists", ce.Name)
+	}
+	if "" == ce.Actual {
+		return fmt.Sprintf("template %s was removed by another process", ce.Name)
+	}
+	return fmt.Sprintf("template %s was modified by another process (revision %s, expected %s) reload and try again", ce.Name, ce.Actual, ce.Expected)
+}
+
+// IsConflict returns true if err was caused by a concurrent change to the store
+func IsConflict(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+// bumpRevision increments the revision counter kept in the template's resourceVersion
+func bumpRevision(appTemp *model.ApplicationTemplate) {
+	revision, _ := strconv.ParseInt(appTemp.ResourceVersion, 10, 64)
+	appTemp.ResourceVersion = strconv.FormatInt(revision+1, 10)
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/maleck13/templator/model"
 	k8 "k8s.io/kubernetes/pkg/api/v1"
@@ -12,18 +14,27 @@ import (
 
 const TEMPLATES_FILE_LOC = "./.templates.json"
 
+// StoreLocation is the store file used by services created with NewTemplateService. It is set from the --store flag
+var StoreLocation = TEMPLATES_FILE_LOC
+
 //may add support for a db if wanted to use as a lib but focus on cli for now
 type TemplateService struct {
 	DataType string
+	Location string
 }
 
 func NewTemplateService(dataType string) *TemplateService {
-	return &TemplateService{DataType: dataType}
+	return &TemplateService{DataType: dataType, Location: StoreLocation}
+}
+
+// NewLocalTemplateService returns a service backed by the store file at location
+func NewLocalTemplateService(location string) *TemplateService {
+	return &TemplateService{DataType: "local", Location: location}
 }
 
 func (ts *TemplateService) GetTemplate(name string) (*model.ApplicationTemplate, error) {
 	if ts.DataType == "local" {
-		templates, err := loadDataFromFile(TEMPLATES_FILE_LOC)
+		templates, err := ts.readStore()
 		if err != nil {
 			return nil, err
 		}
@@ -33,60 +44,214 @@ func (ts *TemplateService) GetTemplate(name string) (*model.ApplicationTemplate,
 }
 
 func (ts *TemplateService) ListTemplates() (map[string]*model.ApplicationTemplate, error) {
-	return loadDataFromFile(TEMPLATES_FILE_LOC)
+	return ts.readStore()
 }
 
+// SaveTemplate stores tempModel under name. The revision of tempModel must match the stored revision, or be empty
+// when the template is new, otherwise a ConflictError is returned rather than overwriting someone else's changes
 func (ts *TemplateService) SaveTemplate(name string, tempModel *model.ApplicationTemplate) error {
-	data, err := loadDataFromFile(TEMPLATES_FILE_LOC)
-	if err != nil {
-		return err
-	}
-	data[name] = tempModel
-	return saveDataToFile(TEMPLATES_FILE_LOC, data)
+	return ts.updateStore(func(data map[string]*model.ApplicationTemplate) error {
+		stored, ok := data[name]
+		if ok && stored.ResourceVersion != tempModel.ResourceVersion {
+			return &ConflictError{Name: name, Expected: tempModel.ResourceVersion, Actual: stored.ResourceVersion}
+		}
+		if !ok && "" != tempModel.ResourceVersion {
+			return &ConflictError{Name: name, Expected: tempModel.ResourceVersion}
+		}
+		bumpRevision(tempModel)
+		data[name] = tempModel
+		return nil
+	})
+}
 
+// RenameTemplate moves a template to a new name rewriting the references inside it. It fails if newName is already taken
+func (ts *TemplateService) RenameTemplate(oldName, newName string) error {
+	return ts.updateStore(func(data map[string]*model.ApplicationTemplate) error {
+		appTemp, ok := data[oldName]
+		if !ok {
+			return fmt.Errorf("no template named %s", oldName)
+		}
+		if _, ok := data[newName]; ok {
+			return fmt.Errorf("a template named %s already exists", newName)
+		}
+		appTemp.Rename(newName)
+		bumpRevision(appTemp)
+		delete(data, oldName)
+		data[newName] = appTemp
+		return nil
+	})
 }
 
 func (ts *TemplateService) DeleteTemplate(name string) error {
-	data, err := loadDataFromFile(TEMPLATES_FILE_LOC)
-	if err != nil {
-		return err
-	}
-	delete(data, name)
-	return saveDataToFile(TEMPLATES_FILE_LOC, data)
+	return ts.updateStore(func(data map[string]*model.ApplicationTemplate) error {
+		delete(data, name)
+		return nil
+	})
 }
 
+// SaveDeployment adds a deployment to the template. A ConflictError is returned rather than replacing a deployment
+// already stored under depName
 func (ts *TemplateService) SaveDeployment(tempName, depName string, tempModel *model.OSTDeploymentConfig) error {
-	data, err := loadDataFromFile(TEMPLATES_FILE_LOC)
-	if appTemp, ok := data[tempName]; ok {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.DeploymentConfigs[depName]; ok {
+			return &ConflictError{Name: tempName, Object: "deployment named " + depName}
+		}
 		if nil == appTemp.DeploymentConfigs {
 			appTemp.DeploymentConfigs = make(map[string]*model.OSTDeploymentConfig)
 		}
 		appTemp.DeploymentConfigs[depName] = tempModel
-		data[tempName] = appTemp
-	}
-	if err != nil {
-		return err
-	}
-	return saveDataToFile(TEMPLATES_FILE_LOC, data)
-
+		return nil
+	})
 }
 
+// SaveService adds a service to the template. A ConflictError is returned rather than replacing a service already
+// stored under depName
 func (ts *TemplateService) SaveService(tempName, depName string, tempModel *k8.Service) error {
-	data, err := loadDataFromFile(TEMPLATES_FILE_LOC)
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.Services[depName]; ok {
+			return &ConflictError{Name: tempName, Object: "service named " + depName}
+		}
+		if nil == appTemp.Services {
+			appTemp.Services = make(map[string]*k8.Service)
+		}
+		appTemp.Services[depName] = tempModel
+		return nil
+	})
+}
+
+func (ts *TemplateService) DeleteDeployment(tempName, depName string) error {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.DeploymentConfigs[depName]; !ok {
+			return fmt.Errorf("no deployment named %s in template %s", depName, tempName)
+		}
+		delete(appTemp.DeploymentConfigs, depName)
+		return nil
+	})
+}
+
+// DeleteDeploymentAndDependants removes the deployment along with the services that select only it and the routes targeting those services
+func (ts *TemplateService) DeleteDeploymentAndDependants(tempName, depName string) error {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.DeploymentConfigs[depName]; !ok {
+			return fmt.Errorf("no deployment named %s in template %s", depName, tempName)
+		}
+		services, routes := appTemp.DeploymentDependants(depName)
+		for _, s := range services {
+			delete(appTemp.Services, s)
+		}
+		for _, r := range routes {
+			delete(appTemp.Routes, r)
+		}
+		delete(appTemp.DeploymentConfigs, depName)
+		return nil
+	})
+}
+
+func (ts *TemplateService) DeleteService(tempName, serviceName string) error {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.Services[serviceName]; !ok {
+			return fmt.Errorf("no service named %s in template %s", serviceName, tempName)
+		}
+		delete(appTemp.Services, serviceName)
+		return nil
+	})
+}
+
+func (ts *TemplateService) DeleteRoute(tempName, routeName string) error {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.Routes[routeName]; !ok {
+			return fmt.Errorf("no route named %s in template %s", routeName, tempName)
+		}
+		delete(appTemp.Routes, routeName)
+		return nil
+	})
+}
+
+func (ts *TemplateService) DeleteVolume(tempName, volumeName string) error {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		if _, ok := appTemp.PersistentVolumes[volumeName]; !ok {
+			return fmt.Errorf("no volume named %s in template %s", volumeName, tempName)
+		}
+		delete(appTemp.PersistentVolumes, volumeName)
+		return nil
+	})
+}
+
+func (ts *TemplateService) DeleteParameter(tempName, paramName string) error {
+	return ts.UpdateTemplate(tempName, func(appTemp *model.ApplicationTemplate) error {
+		for i, p := range appTemp.Parameters {
+			if p.Name == paramName {
+				appTemp.Parameters = append(appTemp.Parameters[:i], appTemp.Parameters[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("no parameter named %s in template %s", paramName, tempName)
+	})
+}
+
+// UpdateTemplate applies update to the named template under the store lock and saves the store if update succeeds
+func (ts *TemplateService) UpdateTemplate(tempName string, update func(appTemp *model.ApplicationTemplate) error) error {
+	return ts.updateStore(func(data map[string]*model.ApplicationTemplate) error {
+		appTemp, ok := data[tempName]
+		if !ok {
+			return fmt.Errorf("no template named %s", tempName)
+		}
+		if err := update(appTemp); err != nil {
+			return err
+		}
+		bumpRevision(appTemp)
+		return nil
+	})
+}
+
+// UpdateTemplates applies update to every template under the store lock. Templates update reports as changed get a
+// new revision and the store is saved if every update succeeds
+func (ts *TemplateService) UpdateTemplates(update func(appTemp *model.ApplicationTemplate) (bool, error)) error {
+	return ts.updateStore(func(data map[string]*model.ApplicationTemplate) error {
+		for _, appTemp := range data {
+			changed, err := update(appTemp)
+			if err != nil {
+				return fmt.Errorf("failed to update template %s %s", appTemp.Name, err.Error())
+			}
+			if changed {
+				bumpRevision(appTemp)
+			}
+		}
+		return nil
+	})
+}
+
+// updateStore holds an exclusive lock on the store while it is loaded, changed by update and written back
+func (ts *TemplateService) updateStore(update func(data map[string]*model.ApplicationTemplate) error) error {
+	unlock, err := lockStore(ts.Location, true)
 	if err != nil {
 		return err
 	}
-	if appTemp, ok := data[tempName]; ok {
-		appTemp.Services[depName] = tempModel
-		data[tempName] = appTemp
+	defer unlock()
+	data, err := loadDataFromFile(ts.Location)
+	if err != nil {
+		return err
 	}
+	if err := update(data); err != nil {
+		return err
+	}
+	return saveDataToFile(ts.Location, data)
+}
 
-	return saveDataToFile(TEMPLATES_FILE_LOC, data)
-
+func (ts *TemplateService) readStore() (map[string]*model.ApplicationTemplate, error) {
+	unlock, err := lockStore(ts.Location, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return loadDataFromFile(ts.Location)
 }
 
 func loadDataFromFile(location string) (map[string]*model.ApplicationTemplate, error) {
 	reader, err := os.Open(location)
+	if os.IsNotExist(err) {
+		return make(map[string]*model.ApplicationTemplate), nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -100,10 +265,30 @@ func loadDataFromFile(location string) (map[string]*model.ApplicationTemplate, e
 
 }
 
+// saveDataToFile writes to a temp file next to the store and renames it over the store so a crash never leaves a partial file
 func saveDataToFile(location string, data map[string]*model.ApplicationTemplate) error {
 	content, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(location, content, 0644)
+	tmp, err := ioutil.TempFile(filepath.Dir(location), filepath.Base(location)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), location)
 }
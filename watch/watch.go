@@ -0,0 +1,63 @@
+// Package watch polls files for changes. Polling is used rather than file system events as the store is replaced
+// by a rename on every save and editors often do the same, which loses event based watches
+package watch
+
+import (
+	"os"
+	"time"
+)
+
+// DEFAULT_INTERVAL is how often files are checked when no interval is given
+const DEFAULT_INTERVAL = time.Second
+
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime int64
+}
+
+// Watcher remembers the last seen state of each file it is asked about
+type Watcher struct {
+	Interval time.Duration
+	stamps   map[string]stamp
+}
+
+// New returns a Watcher checking every interval, or DEFAULT_INTERVAL when interval is not positive
+func New(interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DEFAULT_INTERVAL
+	}
+	return &Watcher{Interval: interval, stamps: make(map[string]stamp)}
+}
+
+// Changed returns the files that were created, removed or modified since the last call. Every file is changed the
+// first time it is seen
+func (w *Watcher) Changed(files []string) []string {
+	var changed []string
+	for _, f := range files {
+		current := stamp{}
+		if info, err := os.Stat(f); err == nil {
+			current = stamp{exists: true, size: info.Size(), modTime: info.ModTime().UnixNano()}
+		}
+		last, seen := w.stamps[f]
+		if !seen || last != current {
+			changed = append(changed, f)
+		}
+		w.stamps[f] = current
+	}
+	return changed
+}
+
+// Run calls onChange with the changed files whenever any of the files returned by files change, starting with all of
+// them. files is called before each check so the caller may change the watched set between checks. Run only returns
+// when onChange returns an error
+func (w *Watcher) Run(files func() []string, onChange func(changed []string) error) error {
+	for {
+		if changed := w.Changed(files()); len(changed) > 0 {
+			if err := onChange(changed); err != nil {
+				return err
+			}
+		}
+		time.Sleep(w.Interval)
+	}
+}